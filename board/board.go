@@ -0,0 +1,305 @@
+// Package board is a bitboard-packed representation of a pips grid, inspired by how
+// chess engines pack squares into 64-bit words. Every playable cell is numbered
+// y*width+x and lives in a handful of uint64 planes (occupancy, one per pip value)
+// instead of a graph of *GridSquare pointers, and each restricted region is a small
+// integer ID into a []RegionState slice rather than a shared pointer. That makes the
+// hot path of the backtracker - "is this placement legal, make it, undo it" - a
+// couple of shifts, ANDs and integer writes instead of four pointer dereferences.
+package board
+
+import "fmt"
+
+// MaxCells is the largest board this package can represent: one bit per cell in a
+// uint64 plane.
+const MaxCells = 64
+
+// Orientation is the direction a domino's second half extends from its anchor cell.
+type Orientation int
+
+const (
+	OrientRight Orientation = iota
+	OrientDown
+	OrientLeft
+	OrientUp
+)
+
+// numOrientations is how many entries the per-cell neighbor/footprint tables need.
+const numOrientations = 4
+
+// RegionType mirrors the restriction kinds a region can enforce.
+type RegionType int
+
+const (
+	RegionNone RegionType = iota
+	RegionGreaterThan
+	RegionLessThan
+	RegionEqual
+	RegionSum
+)
+
+// RegionState is the live, mutable state of one restricted region: its target value
+// (or remaining target, for sum regions) and how many of its squares are still
+// unfilled. Domino placement mutates this in place and undo restores it, so
+// backtracking never has to touch anything beyond a couple of integer fields.
+type RegionState struct {
+	Type           RegionType
+	Arg            int
+	NumSquaresLeft int
+}
+
+func (rs *RegionState) check(value, numSquares int) bool {
+	switch rs.Type {
+	case RegionNone:
+		return true
+	case RegionGreaterThan:
+		return value > rs.Arg
+	case RegionLessThan:
+		return value < rs.Arg
+	case RegionEqual:
+		return rs.Arg == -1 || value == rs.Arg
+	case RegionSum:
+		if rs.Arg-value < 0 {
+			return false
+		}
+		if rs.NumSquaresLeft == numSquares && value != rs.Arg {
+			return false
+		}
+		return true
+	}
+	return false
+}
+
+// Board is a packed bitboard representation of one pips grid.
+type Board struct {
+	width, height int
+
+	playable uint64
+	occupied uint64
+	// valuePlane[v] has bit i set when cell i currently holds pip value v.
+	valuePlane [7]uint64
+
+	neighbor  [MaxCells][numOrientations]int
+	footprint [MaxCells][numOrientations]uint64
+	regionOf  [MaxCells]int
+	regions   []RegionState
+}
+
+// New creates a Board over a width x height grid. Panics if it wouldn't fit in a
+// single uint64 plane, the same way a chess engine would refuse a board bigger than
+// 8x8 for its 64-bit representation.
+func New(width, height int) *Board {
+	if width*height > MaxCells {
+		panic(fmt.Sprintf("board: %dx%d grid has %d cells, more than the %d this representation supports", width, height, width*height, MaxCells))
+	}
+	b := &Board{width: width, height: height}
+	for i := range b.regionOf {
+		b.regionOf[i] = -1
+	}
+	return b
+}
+
+// CellIndex returns the packed cell index for grid coordinates (x, y).
+func (b *Board) CellIndex(x, y int) int {
+	return y*b.width + x
+}
+
+// SetPlayable marks (x, y) as part of the puzzle (as opposed to a blank square).
+func (b *Board) SetPlayable(x, y int) {
+	b.playable |= 1 << uint(b.CellIndex(x, y))
+}
+
+// IsPlayable reports whether cell is part of the puzzle.
+func (b *Board) IsPlayable(cell int) bool {
+	return b.playable&(1<<uint(cell)) != 0
+}
+
+// AddRegion registers a new restricted region and returns its ID.
+func (b *Board) AddRegion(t RegionType, arg int) int {
+	b.regions = append(b.regions, RegionState{Type: t, Arg: arg})
+	return len(b.regions) - 1
+}
+
+// AddCellToRegion assigns cell to regionID and counts it towards that region's
+// NumSquaresLeft. Must be called before Finalize.
+func (b *Board) AddCellToRegion(cell, regionID int) {
+	b.regionOf[cell] = regionID
+	b.regions[regionID].NumSquaresLeft++
+}
+
+// Finalize precomputes the neighbor and footprint tables from the playable mask.
+// Call it once all playable cells and regions are set up.
+func (b *Board) Finalize() {
+	for y := 0; y < b.height; y++ {
+		for x := 0; x < b.width; x++ {
+			cell := b.CellIndex(x, y)
+			if !b.IsPlayable(cell) {
+				continue
+			}
+			b.setNeighbor(cell, OrientRight, x+1, y)
+			b.setNeighbor(cell, OrientDown, x, y+1)
+			b.setNeighbor(cell, OrientLeft, x-1, y)
+			b.setNeighbor(cell, OrientUp, x, y-1)
+		}
+	}
+}
+
+func (b *Board) setNeighbor(cell int, o Orientation, nx, ny int) {
+	if nx < 0 || ny < 0 || nx >= b.width || ny >= b.height {
+		b.neighbor[cell][o] = -1
+		return
+	}
+	n := b.CellIndex(nx, ny)
+	if !b.IsPlayable(n) {
+		b.neighbor[cell][o] = -1
+		return
+	}
+	b.neighbor[cell][o] = n
+	b.footprint[cell][o] = 1<<uint(cell) | 1<<uint(n)
+}
+
+// Neighbor returns the cell adjacent to cell in orientation o, or false if that
+// would fall off the edge of the board or land on a non-playable square.
+func (b *Board) Neighbor(cell int, o Orientation) (int, bool) {
+	n := b.neighbor[cell][o]
+	if n < 0 {
+		return -1, false
+	}
+	return n, true
+}
+
+// Occupied reports whether cell is currently covered by a placed domino.
+func (b *Board) Occupied(cell int) bool {
+	return b.occupied&(1<<uint(cell)) != 0
+}
+
+// PipValue returns the pip value currently occupying cell, if any.
+func (b *Board) PipValue(cell int) (int, bool) {
+	if !b.Occupied(cell) {
+		return 0, false
+	}
+	for v := 0; v <= 6; v++ {
+		if b.valuePlane[v]&(1<<uint(cell)) != 0 {
+			return v, true
+		}
+	}
+	return 0, false
+}
+
+// RegionOf returns cell's region ID, or -1 if it has no restriction.
+func (b *Board) RegionOf(cell int) int {
+	return b.regionOf[cell]
+}
+
+// Region returns a pointer to the live state of regionID, so callers (and the
+// propagate package, via the solver) can read or snapshot it directly.
+func (b *Board) Region(regionID int) *RegionState {
+	return &b.regions[regionID]
+}
+
+// NumRegions returns how many regions have been registered.
+func (b *Board) NumRegions() int {
+	return len(b.regions)
+}
+
+// CheckValue reports whether value alone would satisfy cell's region, the same way
+// a single-square restriction check would.
+func (b *Board) CheckValue(cell, value int) bool {
+	regionID := b.regionOf[cell]
+	if regionID == -1 {
+		return true
+	}
+	return b.regions[regionID].check(value, 1)
+}
+
+// CanOccupy reports whether the two-cell footprint of placing a domino at cell in
+// orientation o is currently free: a single shift-and-AND against the occupancy
+// plane rather than four pointer dereferences.
+func (b *Board) CanOccupy(cell int, o Orientation) (neighbor int, ok bool) {
+	neighbor, ok = b.Neighbor(cell, o)
+	if !ok {
+		return -1, false
+	}
+	if b.footprint[cell][o]&b.occupied != 0 {
+		return -1, false
+	}
+	return neighbor, true
+}
+
+// CanPlace reports whether values v1, v2 would satisfy the restrictions on cell and
+// neighbor respectively, assuming CanOccupy already confirmed the footprint is
+// free. When both squares share a region, eq/sum are checked once as a pair (eq
+// checks v1 against the region, sum checks v1+v2); gt/lt have no such pair form, so
+// both v1 and v2 are checked against the region individually. Otherwise only
+// neighbor is checked, since the candidate search already filtered on cell's value
+// before getting here.
+func (b *Board) CanPlace(cell, neighbor, v1, v2 int) bool {
+	cellRegion, neighborRegion := b.regionOf[cell], b.regionOf[neighbor]
+	if cellRegion != -1 && cellRegion == neighborRegion {
+		rs := &b.regions[cellRegion]
+		switch rs.Type {
+		case RegionEqual:
+			return rs.check(v1, 1)
+		case RegionSum:
+			return rs.check(v1+v2, 2)
+		case RegionGreaterThan, RegionLessThan:
+			return rs.check(v1, 1) && rs.check(v2, 1)
+		}
+		return true
+	}
+	return b.CheckValue(neighbor, v2)
+}
+
+// Assign places values v1 (on cell) and v2 (on neighbor), updates whichever
+// regions they belong to, and returns the function that undoes all of it.
+func (b *Board) Assign(cell, neighbor, v1, v2 int) func() {
+	footprint := uint64(1)<<uint(cell) | uint64(1)<<uint(neighbor)
+	b.occupied |= footprint
+	b.valuePlane[v1] |= 1 << uint(cell)
+	b.valuePlane[v2] |= 1 << uint(neighbor)
+
+	cellRegion, neighborRegion := b.regionOf[cell], b.regionOf[neighbor]
+	cellWasBlank := b.applyFill(cellRegion, v1)
+	neighborWasBlank := b.applyFill(neighborRegion, v2)
+
+	return func() {
+		b.occupied &^= footprint
+		b.valuePlane[v1] &^= 1 << uint(cell)
+		b.valuePlane[v2] &^= 1 << uint(neighbor)
+		b.undoFill(cellRegion, v1, cellWasBlank)
+		b.undoFill(neighborRegion, v2, neighborWasBlank)
+	}
+}
+
+func (b *Board) applyFill(regionID, value int) (wasBlank bool) {
+	if regionID == -1 {
+		return false
+	}
+	rs := &b.regions[regionID]
+	switch rs.Type {
+	case RegionEqual:
+		if rs.Arg == -1 {
+			rs.Arg = value
+			wasBlank = true
+		}
+	case RegionSum:
+		rs.Arg -= value
+		rs.NumSquaresLeft--
+	}
+	return wasBlank
+}
+
+func (b *Board) undoFill(regionID, value int, wasBlank bool) {
+	if regionID == -1 {
+		return
+	}
+	rs := &b.regions[regionID]
+	switch rs.Type {
+	case RegionEqual:
+		if wasBlank {
+			rs.Arg = -1
+		}
+	case RegionSum:
+		rs.Arg += value
+		rs.NumSquaresLeft++
+	}
+}
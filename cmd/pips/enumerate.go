@@ -0,0 +1,54 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/mmandrus/pips-solver/pips"
+)
+
+func cmdEnumerate(args []string) {
+	fs := flag.NewFlagSet("enumerate", flag.ExitOnError)
+	max := fs.Int("max", 1, "maximum number of solutions to find")
+	// The flag package stops parsing at the first non-flag argument, but the
+	// documented usage is "enumerate <file> --max N" with the file first, so pull
+	// the positional argument out wherever it falls before handing the rest to
+	// fs.Parse.
+	var positional []string
+	var flagArgs []string
+	for i := 0; i < len(args); i++ {
+		if strings.HasPrefix(args[i], "-") {
+			flagArgs = append(flagArgs, args[i])
+			if args[i] == "-max" || args[i] == "--max" {
+				i++
+				if i < len(args) {
+					flagArgs = append(flagArgs, args[i])
+				}
+			}
+			continue
+		}
+		positional = append(positional, args[i])
+	}
+	fs.Parse(flagArgs)
+	if len(positional) != 1 {
+		fmt.Fprintln(os.Stderr, "usage: pips enumerate <file> --max N")
+		os.Exit(2)
+	}
+
+	puzzle := loadPuzzle(positional[0])
+	solutions, err := pips.SolveAll(puzzle, *max)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "pips: %v\n", err)
+		os.Exit(2)
+	}
+	if len(solutions) == 0 {
+		fmt.Println("No solution found")
+		os.Exit(1)
+	}
+	for i, sol := range solutions {
+		fmt.Printf("Solution %d:\n", i+1)
+		fmt.Print(sol.String())
+	}
+}
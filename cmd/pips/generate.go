@@ -0,0 +1,40 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/mmandrus/pips-solver/generator"
+)
+
+func cmdGenerate(args []string) {
+	fs := flag.NewFlagSet("generate", flag.ExitOnError)
+	width := fs.Int("width", 4, "board width")
+	height := fs.Int("height", 4, "board height")
+	maxPip := fs.Int("max-pip", 6, "highest pip value to draw synthesized dominoes from")
+	difficulty := fs.Int("difficulty", 3, "difficulty, 1 (easiest) to 5 (hardest)")
+	timeout := fs.Duration("timeout", 10*time.Second, "how long to spend retrying tilings and minimizing regions before returning the best puzzle found so far (larger boards are more likely to hit this)")
+	fs.Parse(args)
+
+	puzzle, err := generator.Generate(generator.Options{
+		Width:       *width,
+		Height:      *height,
+		MaxPipValue: *maxPip,
+		Difficulty:  generator.Difficulty(*difficulty),
+		Deadline:    time.Now().Add(*timeout),
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "pips: %v\n", err)
+		os.Exit(1)
+	}
+
+	data, err := json.MarshalIndent(puzzle, "", "  ")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "pips: encoding generated puzzle: %v\n", err)
+		os.Exit(2)
+	}
+	fmt.Println(string(data))
+}
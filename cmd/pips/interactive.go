@@ -0,0 +1,204 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/mmandrus/pips-solver/pips"
+)
+
+// runInteractive is the original prompt-driven flow: it walks the user through
+// describing a puzzle by hand, then solves it. Kept around for anyone who'd
+// rather not write a puzzle file.
+func runInteractive() {
+	fmt.Println("NYT Pips Puzzle Solver")
+	fmt.Println("======================")
+
+	input := promptForInput("Enter the grid dimensions ['x y']:")
+	x, y := parseDimensions(input)
+	// Initial grid squares, we will gradually populate and refine it from the user input
+	grid := make([][]*pips.GridSquare, y)
+
+	fmt.Println("Let's enter the blank squares...")
+	for i := 0; i < y; i++ {
+		input = promptForInput(fmt.Sprintf("Enter the blank squares for row %d [1-indexed, space-separated]:", i+1))
+		grid[i] = parseRow(x, input, i)
+	}
+
+	fmt.Println("Grid:")
+	for _, row := range grid {
+		for _, square := range row {
+			if square != nil {
+				fmt.Printf("p")
+			} else {
+				fmt.Printf("b")
+			}
+		}
+		fmt.Println()
+	}
+
+	fmt.Println("Now for the restricted regions...")
+	fmt.Println("The format is '<type> <args> <x1> <y1> <x2> <y2>...', 1-indexed")
+	fmt.Println("The types and args are:")
+	fmt.Println("  - greater than: 'gt <value>'")
+	fmt.Println("  - less than: 'lt <value>'")
+	fmt.Println("  - sums to: 'sum <value>'")
+	fmt.Println("  - all equal: 'eq'")
+	fmt.Println("Examples:")
+	fmt.Println("  - 'gt 4 3 1'; square at index (3,1) is greater than 4")
+	fmt.Println("  - 'lt 2 6 6'; square at index (6,6) is less than 2")
+	fmt.Println("  - 'sum 12 5 5 5 6'; two squares at indices (5,5) and (5,6) sum to 12")
+	fmt.Println("  - 'eq 1 1 1 2 2 1 2 2'; a 2x2 region starting at (1,1) and ending at (2,2) are all equal")
+	fmt.Println("Now your turn!")
+
+	for {
+		input = promptForInput("Enter the next restricted region (or 'done' to finish):")
+		if input == "done" {
+			break
+		}
+		// Apply the restricted region to the grid
+		parseRestrictedRegion(input, grid)
+	}
+
+	// Pack the grid and its restrictions into a bitboard, which the solver and every
+	// Domino move now checks and mutates instead of walking *pips.GridSquare pointers.
+	pips.CompileBoard(grid)
+
+	fmt.Println("Finally, enter the dominos...")
+	fmt.Println("Enter them as space-separated pairs of numbers, e.g. '1 2 0 6' represents a 1-2 domino and a 0-6 domino")
+	input = promptForInput("Your turn:")
+	dominoes := parseDominoes(input)
+	// Keep track of moves, we are finally ready to solve the puzzle
+	moveQueue := make(pips.MoveQueue, 0)
+
+	// Constraint propagation is enabled by default; pass false to NewSolver to fall
+	// back to the naive backtracker, e.g. for benchmarking.
+	solver := pips.NewSolver(grid, dominoes, true)
+	if success := solver.Solve(grid, dominoes, &moveQueue); !success {
+		fmt.Println("No solution found")
+		return
+	}
+
+	fmt.Println("Solution found!")
+	fmt.Println(moveQueue.String())
+}
+
+// promptForInput prompts the user for input and returns the raw string
+func promptForInput(line string) string {
+	fmt.Println(line)
+	reader := bufio.NewReader(os.Stdin)
+	input, err := reader.ReadString('\n')
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading input: %v\n", err)
+		return ""
+	}
+	return strings.TrimSpace(input)
+}
+
+func parseDimensions(input string) (int, int) {
+	parts := strings.Split(input, " ")
+	if len(parts) != 2 {
+		return 0, 0
+	}
+	x, err := strconv.Atoi(parts[0])
+	if err != nil {
+		panic(err)
+	}
+	y, err := strconv.Atoi(parts[1])
+	if err != nil {
+		panic(err)
+	}
+	return x, y
+}
+
+func parseRow(width int, input string, rowIndex int) []*pips.GridSquare {
+	row := make([]*pips.GridSquare, width)
+	input = strings.TrimSpace(input)
+	parts := strings.Split(input, " ")
+	blankIndices := make(map[int]bool)
+	if input != "" {
+		for _, part := range parts {
+			num, err := strconv.Atoi(part)
+			if err != nil {
+				panic(err)
+			}
+			blankIndices[num-1] = true
+		}
+	}
+	for i := 0; i < width; i++ {
+		if _, ok := blankIndices[i]; !ok {
+			row[i] = &pips.GridSquare{X: i, Y: rowIndex, Restriction: &pips.Restriction{Type: pips.RestrictionTypeNone}}
+		}
+	}
+	return row
+}
+
+// parseRestrictedRegion parses a restricted region from the input string and assigns the restriction to the grid squares it affects
+func parseRestrictedRegion(input string, grid [][]*pips.GridSquare) {
+	parts := strings.Split(input, " ")
+	typ := pips.RestrictionType(parts[0])
+	restriction := &pips.Restriction{Type: typ}
+	parts = parts[1:]
+	switch typ {
+	case "gt":
+		arg, err := strconv.Atoi(parts[0])
+		if err != nil {
+			panic(err)
+		}
+		restriction.Arg = arg
+		parts = parts[1:]
+	case "lt":
+		arg, err := strconv.Atoi(parts[0])
+		if err != nil {
+			panic(err)
+		}
+		restriction.Arg = arg
+		parts = parts[1:]
+	case "sum":
+		arg, err := strconv.Atoi(parts[0])
+		if err != nil {
+			panic(err)
+		}
+		restriction.Arg = arg
+		parts = parts[1:]
+	case "eq":
+		restriction.Type = pips.RestrictionTypeEqual
+		restriction.Arg = -1
+		parts = parts[1:]
+	}
+	for i := 0; i < len(parts); i += 2 {
+		x, err := strconv.Atoi(parts[i])
+		if err != nil {
+			panic(err)
+		}
+		y, err := strconv.Atoi(parts[i+1])
+		if err != nil {
+			panic(err)
+		}
+		x--
+		y--
+		fmt.Println("Adding restriction to grid square", x, y)
+		grid[y][x].Restriction = restriction
+		restriction.NumSquaresLeft++
+	}
+}
+
+func parseDominoes(input string) pips.DominoSet {
+	dominoes := make(pips.DominoSet, 0)
+	parts := strings.Split(input, " ")
+	for i := 0; i < len(parts); i += 2 {
+		v1, err := strconv.Atoi(parts[i])
+		if err != nil {
+			panic(err)
+		}
+		v2, err := strconv.Atoi(parts[i+1])
+		if err != nil {
+			panic(err)
+		}
+		dominoes = append(dominoes, &pips.Domino{Square1Value: v1, Square2Value: v2})
+	}
+	return dominoes
+}
@@ -0,0 +1,213 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/mmandrus/pips-solver/pips"
+)
+
+// cmdInteractivePlay loads a puzzle file and lets a human play it by hand, one
+// domino move at a time, instead of handing the whole thing to the solver. It's
+// the "let the user drive" counterpart to solve/enumerate: every move still goes
+// through Domino.TryAssign, so an illegal placement is rejected the same way the
+// backtracker would reject it, and 'hint' calls the solver from wherever the
+// board currently sits.
+func cmdInteractivePlay(args []string) {
+	fs := flag.NewFlagSet("interactive-play", flag.ExitOnError)
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "usage: pips interactive-play <file>")
+		os.Exit(2)
+	}
+
+	puzzle := loadPuzzle(fs.Arg(0))
+	grid, dominoes, err := pips.BuildGrid(puzzle)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "pips: %v\n", err)
+		os.Exit(2)
+	}
+	moveQueue := make(pips.MoveQueue, 0)
+
+	fmt.Println("NYT Pips Interactive Play")
+	fmt.Println("=========================")
+	fmt.Println("Commands (all coordinates and domino numbers are 1-indexed):")
+	fmt.Println("  place <domino#> <x> <y>   place a domino's first half on (x,y), second half following its current rotation")
+	fmt.Println("  rotate <domino#>          rotate an unplaced domino 90 degrees clockwise")
+	fmt.Println("  swap <domino#>            swap an unplaced domino's two values")
+	fmt.Println("  undo                      undo the last move")
+	fmt.Println("  hint                      ask the solver what to do next")
+	fmt.Println("  quit                      leave")
+
+	for {
+		fmt.Println()
+		printBoard(grid)
+		printDominoes(dominoes)
+
+		parts := strings.Fields(promptForInput("Your move:"))
+		if len(parts) == 0 {
+			continue
+		}
+
+		switch parts[0] {
+		case "place":
+			handlePlace(parts[1:], grid, dominoes, &moveQueue)
+		case "rotate":
+			handleRotate(parts[1:], dominoes, &moveQueue)
+		case "swap":
+			handleSwap(parts[1:], dominoes, &moveQueue)
+		case "undo":
+			if moveQueue.Pop() == nil {
+				fmt.Println("Nothing to undo.")
+			}
+		case "hint":
+			printHint(pips.FindHint(grid, dominoes, moveQueue))
+		case "quit", "done":
+			return
+		default:
+			fmt.Printf("Unrecognized command %q.\n", parts[0])
+		}
+	}
+}
+
+// dominoArg parses a 1-indexed domino number out of args, reporting it as a valid
+// index into dominoes or printing why it couldn't.
+func dominoArg(args []string, dominoes pips.DominoSet) (int, bool) {
+	if len(args) < 1 {
+		fmt.Println("error: missing domino number")
+		return 0, false
+	}
+	n, err := strconv.Atoi(args[0])
+	if err != nil || n < 1 || n > len(dominoes) {
+		fmt.Printf("error: domino number must be between 1 and %d\n", len(dominoes))
+		return 0, false
+	}
+	return n - 1, true
+}
+
+func handlePlace(args []string, grid [][]*pips.GridSquare, dominoes pips.DominoSet, moveQueue *pips.MoveQueue) {
+	idx, ok := dominoArg(args, dominoes)
+	if !ok {
+		return
+	}
+	if len(args) != 3 {
+		fmt.Println("usage: place <domino#> <x> <y>")
+		return
+	}
+	x, errX := strconv.Atoi(args[1])
+	y, errY := strconv.Atoi(args[2])
+	if errX != nil || errY != nil || x < 1 || y < 1 || y > len(grid) || x > len(grid[y-1]) {
+		fmt.Println("error: (x,y) is outside the grid")
+		return
+	}
+	square := grid[y-1][x-1]
+	if square == nil {
+		fmt.Println("error: that square isn't playable")
+		return
+	}
+	domino := dominoes[idx]
+	if domino.IsAssigned {
+		fmt.Println("error: that domino is already placed")
+		return
+	}
+	if !square.Board.CheckValue(square.Cell, domino.Square1Value) {
+		// TryAssign only re-checks the *neighbor* square's restriction when the
+		// anchor square is alone in its region - same as the solver, it trusts
+		// FindAvailableCandidates already filtered the anchor side, but a human
+		// naming a square directly skips that filter, so check it here instead.
+		fmt.Printf("error: %d doesn't satisfy the restriction on (%d,%d)\n", domino.Square1Value, x, y)
+		return
+	}
+
+	move := &pips.Move{
+		Label:      fmt.Sprintf("Place domino %d-%d at %d,%d", domino.Square1Value, domino.Square2Value, x, y),
+		Domino:     domino,
+		GridSquare: square,
+		MoveType:   pips.MoveTypeAssign,
+	}
+	if !moveQueue.TryPush(move) {
+		fmt.Println("error: that placement doesn't fit there")
+	}
+}
+
+func handleRotate(args []string, dominoes pips.DominoSet, moveQueue *pips.MoveQueue) {
+	idx, ok := dominoArg(args, dominoes)
+	if !ok {
+		return
+	}
+	domino := dominoes[idx]
+	if domino.IsAssigned {
+		fmt.Println("error: that domino is already placed")
+		return
+	}
+	moveQueue.TryPush(&pips.Move{
+		Label:    fmt.Sprintf("Rotate domino %d-%d", domino.Square1Value, domino.Square2Value),
+		Domino:   domino,
+		MoveType: pips.MoveTypeRotate,
+	})
+}
+
+func handleSwap(args []string, dominoes pips.DominoSet, moveQueue *pips.MoveQueue) {
+	idx, ok := dominoArg(args, dominoes)
+	if !ok {
+		return
+	}
+	domino := dominoes[idx]
+	if domino.IsAssigned {
+		fmt.Println("error: that domino is already placed")
+		return
+	}
+	moveQueue.TryPush(&pips.Move{
+		Label:    fmt.Sprintf("Swap domino %d-%d", domino.Square1Value, domino.Square2Value),
+		Domino:   domino,
+		MoveType: pips.MoveTypeSwap,
+	})
+}
+
+// printBoard renders every playable square's current pip value, "_" for a
+// playable square that's still empty, and "." for a non-playable square.
+func printBoard(grid [][]*pips.GridSquare) {
+	for _, row := range grid {
+		for _, square := range row {
+			if square == nil {
+				fmt.Print(". ")
+				continue
+			}
+			if v, ok := square.PipValue(); ok {
+				fmt.Printf("%d ", v)
+				continue
+			}
+			fmt.Print("_ ")
+		}
+		fmt.Println()
+	}
+}
+
+// printDominoes lists every domino still available to place, its current
+// orientation, and the 1-indexed number commands above address it by.
+func printDominoes(dominoes pips.DominoSet) {
+	fmt.Println("Dominoes:")
+	for i, d := range dominoes {
+		if d.IsAssigned {
+			continue
+		}
+		fmt.Printf("  %d: %d-%d\n", i+1, d.Square1Value, d.Square2Value)
+	}
+}
+
+// printHint reports what FindHint discovered in whatever form fits its Kind.
+func printHint(hint pips.Hint) {
+	switch hint.Kind {
+	case pips.HintSolved:
+		fmt.Println("The board is already completely filled.")
+	case pips.HintNextMove:
+		fmt.Println("Hint:", hint.Move.Label)
+	case pips.HintDeadEnd:
+		fmt.Printf("No solution is reachable: square (%d,%d) has no value left that could work.\n", hint.Square.X+1, hint.Square.Y+1)
+	case pips.HintUnsolvable:
+		fmt.Println("No solution is reachable from here.")
+	}
+}
@@ -0,0 +1,62 @@
+// Command pips solves, verifies, and enumerates solutions for NYT Pips puzzles
+// described in the JSON file format documented on pips.Puzzle, or walks a human
+// through playing one interactively.
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/mmandrus/pips-solver/pips"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	switch os.Args[1] {
+	case "solve":
+		cmdSolve(os.Args[2:])
+	case "verify":
+		cmdVerify(os.Args[2:])
+	case "enumerate":
+		cmdEnumerate(os.Args[2:])
+	case "generate":
+		cmdGenerate(os.Args[2:])
+	case "interactive":
+		runInteractive()
+	case "interactive-play":
+		cmdInteractivePlay(os.Args[2:])
+	default:
+		usage()
+		os.Exit(2)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: pips <command> [arguments]")
+	fmt.Fprintln(os.Stderr, "commands:")
+	fmt.Fprintln(os.Stderr, "  solve <file>                 solve a puzzle file and print the move sequence")
+	fmt.Fprintln(os.Stderr, "  verify <file> <solution>     check a proposed assignment against a puzzle's restrictions")
+	fmt.Fprintln(os.Stderr, "  enumerate <file> --max N     find up to N distinct solutions")
+	fmt.Fprintln(os.Stderr, "  generate [flags]             print a freshly generated, uniquely-solvable puzzle")
+	fmt.Fprintln(os.Stderr, "  interactive                  build and solve a puzzle from prompts")
+	fmt.Fprintln(os.Stderr, "  interactive-play <file>      play a puzzle file by hand, with solver-backed hints")
+}
+
+// loadPuzzle reads and parses a puzzle file, exiting the process on failure.
+func loadPuzzle(path string) *pips.Puzzle {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "pips: reading %s: %v\n", path, err)
+		os.Exit(2)
+	}
+	puzzle, err := pips.ParsePuzzle(data)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "pips: %v\n", err)
+		os.Exit(2)
+	}
+	return puzzle
+}
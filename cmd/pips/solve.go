@@ -0,0 +1,30 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/mmandrus/pips-solver/pips"
+)
+
+func cmdSolve(args []string) {
+	fs := flag.NewFlagSet("solve", flag.ExitOnError)
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "usage: pips solve <file>")
+		os.Exit(2)
+	}
+
+	puzzle := loadPuzzle(fs.Arg(0))
+	moveQueue, ok, err := pips.Solve(puzzle)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "pips: %v\n", err)
+		os.Exit(2)
+	}
+	if !ok {
+		fmt.Println("No solution found")
+		os.Exit(1)
+	}
+	fmt.Print(moveQueue.String())
+}
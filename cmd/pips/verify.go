@@ -0,0 +1,43 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/mmandrus/pips-solver/pips"
+)
+
+func cmdVerify(args []string) {
+	fs := flag.NewFlagSet("verify", flag.ExitOnError)
+	fs.Parse(args)
+	if fs.NArg() != 2 {
+		fmt.Fprintln(os.Stderr, "usage: pips verify <file> <solution>")
+		os.Exit(2)
+	}
+
+	puzzle := loadPuzzle(fs.Arg(0))
+
+	data, err := os.ReadFile(fs.Arg(1))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "pips: reading %s: %v\n", fs.Arg(1), err)
+		os.Exit(2)
+	}
+	var assignment pips.Assignment
+	if err := json.Unmarshal(data, &assignment); err != nil {
+		fmt.Fprintf(os.Stderr, "pips: parsing %s: %v\n", fs.Arg(1), err)
+		os.Exit(2)
+	}
+
+	ok, err := pips.Verify(puzzle, &assignment)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "pips: %v\n", err)
+		os.Exit(2)
+	}
+	if !ok {
+		fmt.Println("invalid")
+		os.Exit(1)
+	}
+	fmt.Println("valid")
+}
@@ -0,0 +1,276 @@
+// Package generator synthesizes uniquely-solvable pips puzzles: it tiles a board
+// with random domino placements to get a filled grid, samples candidate restricted
+// regions consistent with the values that tiling produced, then strips regions
+// greedily while pips.IsUnique still holds. The result is a *pips.Puzzle in the
+// same JSON file format pips.ParsePuzzle reads, so it can be piped straight into
+// "pips solve" or "pips verify".
+package generator
+
+import (
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/mmandrus/pips-solver/pips"
+	"github.com/mmandrus/pips-solver/propagate"
+)
+
+// Difficulty scales both the slack Generate allows on sampled "gt"/"lt"
+// restrictions (more slack is a weaker, harder-to-use clue) and how aggressively
+// the minimizer strips restrictions once a unique solution is found. 1 is the
+// easiest puzzle (tight restrictions, most of them kept); 5 the hardest.
+type Difficulty int
+
+const (
+	Easiest Difficulty = 1
+	Hardest Difficulty = 5
+)
+
+// Options configures Generate.
+type Options struct {
+	Width, Height int
+
+	// Dominoes is the domino multiset to tile the board with. Its length must
+	// equal half the number of playable cells. If nil, Generate synthesizes that
+	// many dominoes by sampling value pairs up to MaxPipValue.
+	Dominoes [][2]int
+	// MaxPipValue bounds the pip values Generate synthesizes when Dominoes is
+	// nil. Ignored otherwise.
+	MaxPipValue int
+
+	Difficulty Difficulty
+
+	// Rand sources all randomness. Defaults to a fixed seed so Generate is
+	// reproducible unless a caller supplies their own.
+	Rand *rand.Rand
+
+	// Deadline bounds how long Generate spends retrying tilings and minimizing
+	// regions between calls to pips.IsUnique, returning whatever it has found
+	// so far once it passes. It can only be checked between searches, not
+	// inside one - pips.IsUnique's backtracker has no cancellation of its own
+	// - so it bounds the number of attempts and minimize's stripping, not the
+	// cost of any single search; see maxPracticalCells for what actually keeps
+	// a single search from running unbounded. Zero means defaultGenerateDeadline
+	// from when Generate is called. A puzzle returned this way is still
+	// guaranteed unique (Generate never returns one that isn't) but may retain
+	// more restrictions than Difficulty asked for, since minimize stops
+	// stripping once the deadline passes.
+	Deadline time.Time
+}
+
+// defaultGenerateDeadline is the Deadline Generate applies when a caller
+// doesn't set one. It's generous enough that every board size generator_test
+// exercises finishes well under it.
+const defaultGenerateDeadline = 10 * time.Second
+
+// maxPracticalCells is the largest board Generate will attempt. pips.IsUnique's
+// backtracking search has no cancellation of its own, so Deadline can only stop
+// Generate between searches, never inside one - and a single search's cost
+// grows fast enough with board size that boards much past the 4x4 (16-cell)
+// boards generator_test exercises can take minutes to finish a single search
+// (observed: a 6x6/36-cell board ran over 3 minutes without returning).
+// Generate rejects anything larger outright rather than risk hanging on one
+// in-flight search Deadline can't interrupt.
+const maxPracticalCells = 16
+
+// maxGenerateAttempts bounds how many random tilings Generate will try to pin
+// down with sampleRegions's varied gt/lt/sum/eq windows before giving up on
+// variety and falling back to pinPerCell, which is always unique: a fresh
+// tiling's sampled regions aren't always enough (several windows can each look
+// restrictive on their own while still leaving more than one grid that
+// satisfies all of them at once), and on a board with enough cells that
+// becomes the common case rather than the exception, so this stays low enough
+// that Generate reaches the always-unique fallback quickly instead of burning
+// time on attempts that were never going to land.
+const maxGenerateAttempts = 20
+
+// Generate produces an Options.Width x Options.Height puzzle with exactly one
+// solution. Each attempt has three stages: tile the board with random domino
+// placements to get a filled grid (tile), sample restricted regions that cover
+// every cell and are consistent with the filled grid (sampleRegions), then -
+// once that fully-restricted puzzle is confirmed unique - strip as many regions
+// as possible while it stays uniquely solvable (minimize). If no attempt's
+// sampled regions turn out unique, Generate falls back to pinPerCell, a region
+// set that always is, so the "exactly one solution" guarantee never depends on
+// getting lucky. Opts.Deadline bounds the whole attempt/minimize process, since
+// pips.IsUnique's cost grows quickly with board size; see Options.Deadline.
+func Generate(opts Options) (*pips.Puzzle, error) {
+	if opts.Width <= 0 || opts.Height <= 0 {
+		return nil, fmt.Errorf("generator: width and height must be positive, got %dx%d", opts.Width, opts.Height)
+	}
+	numCells := opts.Width * opts.Height
+	if numCells%2 != 0 {
+		return nil, fmt.Errorf("generator: %dx%d board has an odd number of cells, can't be tiled by dominoes", opts.Width, opts.Height)
+	}
+	if numCells > maxPracticalCells {
+		return nil, fmt.Errorf("generator: %dx%d board has %d cells, which exceeds the %d-cell practical limit (see maxPracticalCells)", opts.Width, opts.Height, numCells, maxPracticalCells)
+	}
+
+	rnd := opts.Rand
+	if rnd == nil {
+		rnd = rand.New(rand.NewSource(1))
+	}
+	deadline := opts.Deadline
+	if deadline.IsZero() {
+		deadline = time.Now().Add(defaultGenerateDeadline)
+	}
+
+	dominoes := opts.Dominoes
+	if dominoes != nil && len(dominoes) != numCells/2 {
+		return nil, fmt.Errorf("generator: %dx%d board needs %d dominoes, got %d", opts.Width, opts.Height, numCells/2, len(dominoes))
+	}
+	// MaxPipValue only matters when Generate has to synthesize dominoes itself,
+	// but it still has to fit the pip range the solver's domains (and
+	// pips.Verify) assume.
+	if dominoes == nil && (opts.MaxPipValue < 0 || opts.MaxPipValue > propagate.MaxPipValue) {
+		return nil, fmt.Errorf("generator: MaxPipValue must be between 0 and %d, got %d", propagate.MaxPipValue, opts.MaxPipValue)
+	}
+
+	var lastDominoes [][2]int
+	var lastValues []int
+	for attempt := 0; attempt < maxGenerateAttempts && !time.Now().After(deadline); attempt++ {
+		attemptDominoes := dominoes
+		if attemptDominoes == nil {
+			attemptDominoes = synthesizeDominoes(numCells/2, opts.MaxPipValue, rnd)
+		}
+
+		pairs, ok := tile(opts.Width, opts.Height, rnd)
+		if !ok {
+			return nil, fmt.Errorf("generator: found no domino tiling of a %dx%d board", opts.Width, opts.Height)
+		}
+		values := assignValues(opts.Width, pairs, attemptDominoes, rnd)
+		lastDominoes, lastValues = attemptDominoes, values
+
+		p := &pips.Puzzle{
+			Width:    opts.Width,
+			Height:   opts.Height,
+			Dominoes: attemptDominoes,
+			Regions:  sampleRegions(opts.Width, opts.Height, values, opts.Difficulty, rnd),
+		}
+		if !pips.IsUnique(p) {
+			// Every cell is restricted as tightly as this attempt's tiling allows
+			// and it's still ambiguous; no amount of stripping fixes that, so
+			// retry with a fresh tiling instead.
+			continue
+		}
+		p.Regions = minimize(p, p.Regions, opts.Difficulty, rnd, deadline)
+		return p, nil
+	}
+
+	// Either none of the varied layouts panned out, or the deadline ran out
+	// before one did. pinPerCell's single-cell "sum" regions pin every cell's
+	// value exactly, so the puzzle they describe has only the one solution
+	// that produced them - minimize can still thin that down per difficulty,
+	// but the result is guaranteed unique before it even runs, so it's a safe
+	// fallback however Generate got here.
+	if lastDominoes == nil {
+		// The deadline passed before even the first attempt's tile/assignValues
+		// ran; synthesize one attempt's worth of data so pinPerCell still has
+		// something to pin.
+		attemptDominoes := dominoes
+		if attemptDominoes == nil {
+			attemptDominoes = synthesizeDominoes(numCells/2, opts.MaxPipValue, rnd)
+		}
+		pairs, ok := tile(opts.Width, opts.Height, rnd)
+		if !ok {
+			return nil, fmt.Errorf("generator: found no domino tiling of a %dx%d board", opts.Width, opts.Height)
+		}
+		lastDominoes = attemptDominoes
+		lastValues = assignValues(opts.Width, pairs, attemptDominoes, rnd)
+	}
+	p := &pips.Puzzle{
+		Width:    opts.Width,
+		Height:   opts.Height,
+		Dominoes: lastDominoes,
+		Regions:  pinPerCell(opts.Width, opts.Height, lastValues),
+	}
+	p.Regions = minimize(p, p.Regions, opts.Difficulty, rnd, deadline)
+	return p, nil
+}
+
+// tile finds a random perfect domino tiling of a width x height rectangle: a
+// partition of every cell into adjacent pairs. It backtracks on whichever
+// neighbor direction it tries first, in a random order, so repeated calls with
+// different Rand state explore different tilings instead of always the same
+// brick pattern.
+func tile(width, height int, rnd *rand.Rand) (pairs [][2]int, ok bool) {
+	numCells := width * height
+	filled := make([]bool, numCells)
+
+	var place func(next int) bool
+	place = func(next int) bool {
+		for next < numCells && filled[next] {
+			next++
+		}
+		if next == numCells {
+			return true
+		}
+		x, y := next%width, next/width
+
+		type offset struct{ dx, dy int }
+		dirs := []offset{{1, 0}, {0, 1}, {-1, 0}, {0, -1}}
+		rnd.Shuffle(len(dirs), func(i, j int) { dirs[i], dirs[j] = dirs[j], dirs[i] })
+
+		for _, d := range dirs {
+			nx, ny := x+d.dx, y+d.dy
+			if nx < 0 || ny < 0 || nx >= width || ny >= height {
+				continue
+			}
+			neighbor := ny*width + nx
+			if filled[neighbor] {
+				continue
+			}
+			filled[next], filled[neighbor] = true, true
+			pairs = append(pairs, [2]int{next, neighbor})
+			if place(next + 1) {
+				return true
+			}
+			filled[next], filled[neighbor] = false, false
+			pairs = pairs[:len(pairs)-1]
+		}
+		return false
+	}
+
+	ok = place(0)
+	return pairs, ok
+}
+
+// synthesizeDominoes builds n dominoes with values sampled uniformly from
+// [0, maxPipValue], with replacement; real NYT Pips domino sets frequently repeat
+// values, so there's no need to draw from a fixed double-N set without repeats.
+func synthesizeDominoes(n, maxPipValue int, rnd *rand.Rand) [][2]int {
+	dominoes := make([][2]int, n)
+	for i := range dominoes {
+		dominoes[i] = [2]int{rnd.Intn(maxPipValue + 1), rnd.Intn(maxPipValue + 1)}
+	}
+	return dominoes
+}
+
+// assignValues randomly matches dominoes to tile's pairs (one each, each domino
+// flipped with 50/50 odds) and returns the resulting pip value at every cell.
+func assignValues(width int, pairs [][2]int, dominoes [][2]int, rnd *rand.Rand) []int {
+	order := rnd.Perm(len(dominoes))
+
+	height := 0
+	for _, pair := range pairs {
+		for _, cell := range pair {
+			if y := cell/width + 1; y > height {
+				height = y
+			}
+		}
+	}
+	values := make([]int, width*height)
+	for i := range values {
+		values[i] = -1
+	}
+
+	for i, pair := range pairs {
+		v1, v2 := dominoes[order[i]][0], dominoes[order[i]][1]
+		if rnd.Intn(2) == 0 {
+			v1, v2 = v2, v1
+		}
+		values[pair[0]] = v1
+		values[pair[1]] = v2
+	}
+	return values
+}
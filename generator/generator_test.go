@@ -0,0 +1,73 @@
+package generator
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/mmandrus/pips-solver/pips"
+)
+
+func TestGenerateProducesUniquelySolvablePuzzle(t *testing.T) {
+	for _, difficulty := range []Difficulty{Easiest, 3, Hardest} {
+		for seed := int64(1); seed <= 5; seed++ {
+			p, err := Generate(Options{
+				Width: 4, Height: 4, MaxPipValue: 4, Difficulty: difficulty,
+				Rand: rand.New(rand.NewSource(seed)),
+			})
+			if err != nil {
+				t.Fatalf("difficulty %d seed %d: Generate returned error: %v", difficulty, seed, err)
+			}
+			if !pips.IsUnique(p) {
+				t.Fatalf("difficulty %d seed %d: generated puzzle is not uniquely solvable", difficulty, seed)
+			}
+		}
+	}
+}
+
+func TestGenerateFallsBackToPinPerCellWhenSamplingNeverLands(t *testing.T) {
+	// A board sampleRegions can't realistically pin on its own: regionFor on
+	// maxGenerateAttempts consecutive attempts still has to produce *some*
+	// puzzle, and that puzzle must still be genuinely unique.
+	p, err := Generate(Options{
+		Width: 4, Height: 4, MaxPipValue: 2, Difficulty: Easiest,
+		Rand: rand.New(rand.NewSource(99)),
+	})
+	if err != nil {
+		t.Fatalf("Generate returned error: %v", err)
+	}
+	if !pips.IsUnique(p) {
+		t.Fatal("Generate returned a puzzle that pips.IsUnique disagrees is unique")
+	}
+}
+
+func TestGenerateRejectsOddCellCount(t *testing.T) {
+	_, err := Generate(Options{Width: 3, Height: 1, MaxPipValue: 3})
+	if err == nil {
+		t.Fatal("Generate on a 3x1 board (odd cell count) returned no error, want one")
+	}
+}
+
+func TestGenerateRejectsMismatchedDominoCount(t *testing.T) {
+	_, err := Generate(Options{Width: 2, Height: 2, Dominoes: [][2]int{{1, 2}}})
+	if err == nil {
+		t.Fatal("Generate with too few dominoes for the board returned no error, want one")
+	}
+}
+
+func TestGenerateRejectsOutOfRangeMaxPipValue(t *testing.T) {
+	for _, maxPip := range []int{-1, 7} {
+		if _, err := Generate(Options{Width: 2, Height: 2, MaxPipValue: maxPip}); err == nil {
+			t.Fatalf("Generate with MaxPipValue=%d returned no error, want one", maxPip)
+		}
+	}
+}
+
+func TestGenerateRejectsBoardsPastMaxPracticalCells(t *testing.T) {
+	// 6x6 is the size the maxPracticalCells limit was added to reject: a single
+	// pips.IsUnique search on a board that size has been observed to run for
+	// minutes, far past anything Deadline could recover from between searches.
+	_, err := Generate(Options{Width: 6, Height: 6, MaxPipValue: 4})
+	if err == nil {
+		t.Fatal("Generate on a 6x6 board (36 cells) returned no error, want one rejecting it as impractically large")
+	}
+}
@@ -0,0 +1,208 @@
+package generator
+
+import (
+	"math/rand"
+	"time"
+
+	"github.com/mmandrus/pips-solver/pips"
+	"github.com/mmandrus/pips-solver/propagate"
+)
+
+// rectShapes are the (width, height) footprints sampleRegions draws candidate
+// regions from: every single cell, every adjacent pair, and every 2-4 cell
+// rectangle up to 1x4/4x1/2x2.
+var rectShapes = [][2]int{
+	{1, 1},
+	{1, 2}, {2, 1},
+	{1, 3}, {3, 1},
+	{1, 4}, {4, 1},
+	{2, 2},
+}
+
+// sampleRegions partitions the board into a random set of candidate
+// pips.RegionSpecs, each with a restriction type and Arg consistent with values -
+// the pip grid a tiling already produced. A GridSquare holds at most one
+// *Restriction (see model.go), so the candidates must be cell-disjoint; they also
+// need to cover every cell; starting minimize from the strongest possible clue set
+// (every cell pinned) gives it the most room to strip clues back down. sampleRegions
+// tries the bigger rectShapes windows first, in random order, then covers whatever
+// cells are left with single-cell regions. gt/lt regions get slack scaled by
+// difficulty, so a harder puzzle's numeric clues are looser.
+func sampleRegions(width, height int, values []int, difficulty Difficulty, rnd *rand.Rand) []pips.RegionSpec {
+	type window struct{ x, y, w, h int }
+	var windows []window
+	for _, shape := range rectShapes {
+		w, h := shape[0], shape[1]
+		if w == 1 && h == 1 {
+			continue // single cells are the fallback filler below, not a random draw
+		}
+		for y := 0; y+h <= height; y++ {
+			for x := 0; x+w <= width; x++ {
+				windows = append(windows, window{x, y, w, h})
+			}
+		}
+	}
+	rnd.Shuffle(len(windows), func(i, j int) { windows[i], windows[j] = windows[j], windows[i] })
+
+	claimed := make([]bool, width*height)
+	var candidates []pips.RegionSpec
+	for _, win := range windows {
+		cells := make([]pips.Coord, 0, win.w*win.h)
+		free := true
+		for dy := 0; dy < win.h; dy++ {
+			for dx := 0; dx < win.w; dx++ {
+				c := pips.Coord{X: win.x + dx, Y: win.y + dy}
+				if claimed[c.Y*width+c.X] {
+					free = false
+				}
+				cells = append(cells, c)
+			}
+		}
+		if !free {
+			continue
+		}
+		candidates = append(candidates, regionFor(cells, values, width, difficulty, rnd))
+		for _, c := range cells {
+			claimed[c.Y*width+c.X] = true
+		}
+	}
+
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			if claimed[y*width+x] {
+				continue
+			}
+			cells := []pips.Coord{{X: x, Y: y}}
+			candidates = append(candidates, regionFor(cells, values, width, difficulty, rnd))
+		}
+	}
+	return candidates
+}
+
+// regionFor picks a restriction type consistent with cells' actual values and
+// returns the RegionSpec it implies. "eq" is only offered when cells has more
+// than one cell and they're all actually equal - a single-cell eq region would
+// be trivially satisfied by any value, so it's not worth sampling. "gt"/"lt" are
+// only offered when their Arg would actually exclude some value - a gt region
+// whose Arg ends up below 0, or an lt region whose Arg ends up above
+// propagate.MaxPipValue, rules nothing out and would be a clue in name only.
+func regionFor(cells []pips.Coord, values []int, width int, difficulty Difficulty, rnd *rand.Rand) pips.RegionSpec {
+	valueAt := func(c pips.Coord) int { return values[c.Y*width+c.X] }
+
+	min, max, sum := valueAt(cells[0]), valueAt(cells[0]), 0
+	allEqual := true
+	for _, c := range cells {
+		v := valueAt(c)
+		sum += v
+		if v < min {
+			min = v
+		}
+		if v > max {
+			max = v
+		}
+		if v != valueAt(cells[0]) {
+			allEqual = false
+		}
+	}
+
+	// slack widens the margin gt/lt leaves between the true min/max and the
+	// restriction's Arg: 0 at Easiest (the tightest possible clue), growing with
+	// difficulty so the clue rules out less.
+	slack := int(difficulty) - 1
+	gtArg := min - 1 - slack
+	ltArg := max + 1 + slack
+
+	// A single-cell region only pins its cell exactly under "sum" (remainder 0,
+	// no squares left): gt/lt always leave some values above/below the bound
+	// still possible, so offering them here would make sampleRegions's claim of
+	// "every cell pinned" false for whichever cells land on the single-cell
+	// fallback.
+	types := []pips.RestrictionType{pips.RestrictionTypeSumsTo}
+	if len(cells) > 1 {
+		if gtArg >= 0 {
+			types = append(types, pips.RestrictionTypeGreaterThan)
+		}
+		if ltArg <= propagate.MaxPipValue {
+			types = append(types, pips.RestrictionTypeLessThan)
+		}
+		if allEqual {
+			types = append(types, pips.RestrictionTypeEqual)
+		}
+	}
+	typ := types[rnd.Intn(len(types))]
+
+	spec := pips.RegionSpec{Type: typ, Cells: cells}
+	switch typ {
+	case pips.RestrictionTypeGreaterThan:
+		spec.Arg = gtArg
+	case pips.RestrictionTypeLessThan:
+		spec.Arg = ltArg
+	case pips.RestrictionTypeSumsTo:
+		spec.Arg = sum
+	case pips.RestrictionTypeEqual:
+		// Arg is ignored for eq; BuildGrid always treats it as unset.
+	}
+	return spec
+}
+
+// pinPerCell returns one single-cell "sum" region per cell, each pinned to that
+// cell's actual value. A single-cell sum region leaves no remainder and no
+// other square to share it with, so it forces exactly that value - unlike
+// sampleRegions's windowed candidates, this set can never be ambiguous,
+// regardless of how the board's dominoes overlap in value.
+func pinPerCell(width, height int, values []int) []pips.RegionSpec {
+	regions := make([]pips.RegionSpec, 0, width*height)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			v := values[y*width+x]
+			regions = append(regions, pips.RegionSpec{
+				Type:  pips.RestrictionTypeSumsTo,
+				Arg:   v,
+				Cells: []pips.Coord{{X: x, Y: y}},
+			})
+		}
+	}
+	return regions
+}
+
+// minimize starts from candidates - a caller-confirmed-unique puzzle's full
+// region set - and tries to strip each one, in a random order, keeping the
+// removal only when the puzzle stays uniquely solvable without it. Higher
+// difficulty makes minimize attempt more removals, so harder puzzles end up
+// retaining fewer restrictions. It gives up and returns whatever it has kept
+// so far once deadline passes, trading a possibly-less-stripped puzzle for a
+// guarantee that minimize itself doesn't become the slow part of Generate.
+func minimize(base *pips.Puzzle, candidates []pips.RegionSpec, difficulty Difficulty, rnd *rand.Rand, deadline time.Time) []pips.RegionSpec {
+	kept := append([]pips.RegionSpec(nil), candidates...)
+
+	// attemptProbability scales from 0.2 at Easiest to 1.0 at Hardest: easy
+	// puzzles leave most sampled clues in place, hard ones try to strip nearly
+	// everything they can get away with.
+	attemptProbability := 0.2 + 0.2*float64(difficulty-Easiest)
+
+	order := rnd.Perm(len(kept))
+	for _, i := range order {
+		if time.Now().After(deadline) {
+			return kept
+		}
+		if rnd.Float64() > attemptProbability {
+			continue
+		}
+		without := make([]pips.RegionSpec, 0, len(kept)-1)
+		for j, spec := range kept {
+			if j != i {
+				without = append(without, spec)
+			}
+		}
+		trial := *base
+		trial.Regions = without
+		if pips.IsUnique(&trial) {
+			kept = without
+			// Re-index order against the shrunk slice: the remaining entries of
+			// order that referenced indices into kept are now stale, so just
+			// restart the scan over what's left.
+			return minimize(base, kept, difficulty, rnd, deadline)
+		}
+	}
+	return kept
+}
@@ -0,0 +1,233 @@
+package pips
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mmandrus/pips-solver/propagate"
+)
+
+// Placement is which domino, and which half of it, ended up on one board cell.
+type Placement struct {
+	DominoID int
+	Half     int // 0 selects the domino's Square1Value, 1 its Square2Value
+}
+
+// Solution is an immutable snapshot of one complete, distinct way to solve a
+// puzzle: which domino (and which half) ended up on every playable cell, plus the
+// pip value that implies. The latter is what canonical-hash dedup and printing
+// actually need, so it's captured alongside rather than recomputed every time.
+type Solution struct {
+	Width, Height int
+	// Placements[cell] is nil for a non-playable cell.
+	Placements []*Placement
+	// Values[cell] is the pip value placed on that cell, or -1 for a non-playable
+	// cell.
+	Values []int
+}
+
+// String renders the pip value at every playable cell as a grid, one row per
+// line, with "." marking a non-playable cell.
+func (s Solution) String() string {
+	var b strings.Builder
+	for y := 0; y < s.Height; y++ {
+		for x := 0; x < s.Width; x++ {
+			if v := s.Values[y*s.Width+x]; v == -1 {
+				b.WriteString(". ")
+			} else {
+				fmt.Fprintf(&b, "%d ", v)
+			}
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// key is a canonical hash of the pip-value grid: two solutions that differ only by
+// which of two identically-valued dominoes landed where - domino-swap symmetry -
+// produce the same grid and so the same key.
+func (s *Solution) key() string {
+	b := make([]byte, len(s.Values))
+	for i, v := range s.Values {
+		b[i] = byte(v + 1) // shift so a blank cell (-1) can't collide with value 0
+	}
+	return string(b)
+}
+
+// SolveAll returns up to limit distinct solutions to p, deduped by the pip-value
+// grid each produces. limit == 1 behaves exactly like Solve: it returns the first
+// solution the backtracker finds, via the same search. Returns an error if p
+// itself is malformed (see BuildGrid), without attempting to solve it.
+func SolveAll(p *Puzzle, limit int) ([]Solution, error) {
+	if limit <= 0 {
+		return nil, nil
+	}
+	grid, dominoes, err := BuildGrid(p)
+	if err != nil {
+		return nil, err
+	}
+	solver := NewSolver(grid, dominoes, true)
+	moveQueue := make(MoveQueue, 0)
+
+	var solutions []Solution
+	seen := make(map[string]bool)
+	solver.enumerate(grid, dominoes, &moveQueue, limit, &solutions, seen)
+	return solutions, nil
+}
+
+// IsUnique reports whether p has exactly one distinct solution. Real NYT Pips
+// puzzles are meant to have one, so this is the building block both puzzle
+// authors and the generator use to check their work. A malformed p (see
+// BuildGrid) can never be uniquely solvable, so it reports false rather than
+// surfacing SolveAll's error - callers that need to know why should call
+// SolveAll directly.
+func IsUnique(p *Puzzle) bool {
+	solutions, err := SolveAll(p, 2)
+	if err != nil {
+		return false
+	}
+	return len(solutions) == 1
+}
+
+// captureSolution snapshots the current fully-assigned board as a Solution, by
+// walking moveQueue for the Assign moves that placed each domino. A domino's
+// orientation never changes again once it's assigned (FindAvailableCandidates
+// skips assigned dominoes), so candidate.Domino.NeighborCell is still accurate
+// here.
+func (s *Solver) captureSolution(grid [][]*GridSquare, moveQueue MoveQueue) Solution {
+	height := len(grid)
+	width := 0
+	if height > 0 {
+		width = len(grid[0])
+	}
+	numCells := width * height
+
+	sol := Solution{
+		Width:      width,
+		Height:     height,
+		Placements: make([]*Placement, numCells),
+		Values:     make([]int, numCells),
+	}
+	for i := range sol.Values {
+		sol.Values[i] = -1
+	}
+
+	for _, move := range moveQueue {
+		if move.Pruned || move.MoveType != MoveTypeAssign {
+			continue
+		}
+		cell1 := move.GridSquare.Cell
+		cell2, _ := move.Domino.NeighborCell(move.GridSquare)
+		dominoID := s.dominoIndex[move.Domino]
+
+		sol.Placements[cell1] = &Placement{DominoID: dominoID, Half: 0}
+		sol.Placements[cell2] = &Placement{DominoID: dominoID, Half: 1}
+		sol.Values[cell1] = move.Domino.Square1Value
+		sol.Values[cell2] = move.Domino.Square2Value
+	}
+	return sol
+}
+
+// enumerate is makeNextMove's sibling for full-search mode: instead of returning
+// on the first complete assignment, it records every distinct one (by
+// Solution.key) and keeps backtracking, until it has collected limit of them or
+// exhausted the search. Its return value means "stop searching entirely" rather
+// than "solved", so every call site that would have returned true on success
+// instead propagates stop upward the same way.
+func (s *Solver) enumerate(grid [][]*GridSquare, dominoes DominoSet, moveQueue *MoveQueue, limit int, solutions *[]Solution, seen map[string]bool) (stop bool) {
+	emptySquare := pickEmptySquare(grid)
+	if emptySquare == nil {
+		sol := s.captureSolution(grid, *moveQueue)
+		if !seen[sol.key()] {
+			seen[sol.key()] = true
+			*solutions = append(*solutions, sol)
+		}
+		return len(*solutions) >= limit
+	}
+
+	candidates := dominoes.FindAvailableCandidates(emptySquare)
+	if len(candidates) == 0 {
+		if len(dominoes) == 0 {
+			// success condition: we have assigned all dominos, mirroring
+			// makeNextMove's same check.
+			sol := s.captureSolution(grid, *moveQueue)
+			if !seen[sol.key()] {
+				seen[sol.key()] = true
+				*solutions = append(*solutions, sol)
+			}
+			return len(*solutions) >= limit
+		}
+		// failure condition: none of the dominos we have left can satisfy the puzzle
+		return false
+	}
+
+	for _, candidate := range candidates {
+		numIterations := 8
+		if !candidate.isRightMatch {
+			numIterations /= 2
+		}
+		if !candidate.isLeftMatch {
+			numIterations /= 2
+			moveQueue.TryPush(&Move{
+				Label:      fmt.Sprintf("Swap domino %d-%d", candidate.Domino.Square1Value, candidate.Domino.Square2Value),
+				Domino:     candidate.Domino,
+				GridSquare: emptySquare,
+				MoveType:   MoveTypeSwap,
+			})
+			defer func() {
+				if !stop {
+					moveQueue.Pop()
+				}
+			}()
+		}
+		for i := 0; i < numIterations; i++ {
+			move := &Move{
+				Label:      fmt.Sprintf("Assign domino %d-%d to square %d,%d", candidate.Domino.Square1Value, candidate.Domino.Square2Value, emptySquare.X+1, emptySquare.Y+1),
+				Domino:     candidate.Domino,
+				GridSquare: emptySquare,
+				MoveType:   MoveTypeAssign,
+			}
+			if moveQueue.TryPush(move) {
+				cpOK := true
+				var cpSnapshot *propagate.Snapshot
+				if s.EnableCP {
+					cpSnapshot = s.cp.Save()
+					neighborCell, _ := candidate.Domino.NeighborCell(emptySquare)
+					s.syncRegions()
+					s.cp.AssignDomino(
+						s.dominoIndex[candidate.Domino],
+						s.squareIndexByCell[emptySquare.Cell], candidate.Domino.Square1Value,
+						s.squareIndexByCell[neighborCell], candidate.Domino.Square2Value,
+					)
+					cpOK = s.cp.Propagate()
+				}
+				if cpOK {
+					if s.enumerate(grid, dominoes, moveQueue, limit, solutions, seen) {
+						stop = true
+					}
+				}
+				if s.EnableCP {
+					s.cp.Restore(cpSnapshot)
+				}
+				moveQueue.Pop()
+				if stop {
+					return true
+				}
+			}
+			move = &Move{
+				Label:      fmt.Sprintf("Rotate domino %d-%d", candidate.Domino.Square1Value, candidate.Domino.Square2Value),
+				Domino:     candidate.Domino,
+				GridSquare: emptySquare,
+				MoveType:   MoveTypeRotate,
+			}
+			moveQueue.TryPush(move)
+			defer func() {
+				if !stop {
+					moveQueue.Pop()
+				}
+			}()
+		}
+	}
+
+	return false
+}
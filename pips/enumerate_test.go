@@ -0,0 +1,67 @@
+package pips
+
+import "testing"
+
+// twoCellSumPuzzle has one sum region covering both cells of a 2x1 board and a
+// single domino that can satisfy it in only one of its two orientations.
+func twoCellSumPuzzle() *Puzzle {
+	return &Puzzle{
+		Width: 2, Height: 1,
+		Regions:  []RegionSpec{{Type: RestrictionTypeSumsTo, Arg: 3, Cells: []Coord{{X: 0, Y: 0}, {X: 1, Y: 0}}}},
+		Dominoes: [][2]int{{1, 2}},
+	}
+}
+
+func TestSolveAllLimitOneMatchesVerify(t *testing.T) {
+	p := twoCellSumPuzzle()
+	solutions, err := SolveAll(p, 1)
+	if err != nil {
+		t.Fatalf("SolveAll returned error: %v", err)
+	}
+	if len(solutions) != 1 {
+		t.Fatalf("SolveAll(p, 1) returned %d solutions, want 1", len(solutions))
+	}
+
+	values := make([][]int, p.Height)
+	for y := range values {
+		values[y] = solutions[0].Values[y*p.Width : (y+1)*p.Width]
+	}
+	ok, err := Verify(p, &Assignment{Values: values})
+	if err != nil {
+		t.Fatalf("Verify returned error: %v", err)
+	}
+	if !ok {
+		t.Fatalf("SolveAll(p, 1) grid %v does not satisfy p's restrictions", values)
+	}
+}
+
+func TestIsUniqueDedupesDominoSwapSymmetry(t *testing.T) {
+	// A 1x4 strip only tiles one way (two adjacent horizontal pairs), and a gt
+	// restriction on each pair's first cell pins its orientation, so the only
+	// remaining freedom is which of the two identical 1-2 dominoes physically
+	// occupies which pair - a pure domino-identity swap that must still dedup to
+	// one distinct solution grid.
+	p := &Puzzle{
+		Width: 4, Height: 1,
+		Regions: []RegionSpec{
+			{Type: RestrictionTypeGreaterThan, Arg: 1, Cells: []Coord{{X: 0, Y: 0}}},
+			{Type: RestrictionTypeGreaterThan, Arg: 1, Cells: []Coord{{X: 2, Y: 0}}},
+		},
+		Dominoes: [][2]int{{1, 2}, {1, 2}},
+	}
+	if !IsUnique(p) {
+		t.Fatal("IsUnique(p) = false, want true: domino-swap symmetry should dedup to one solution")
+	}
+}
+
+func TestIsUniqueFalseWhenMultipleDistinctSolutionsExist(t *testing.T) {
+	// No restrictions on a 2x1 board with two distinct dominoes: either domino
+	// can occupy either orientation, giving two distinct grids.
+	p := &Puzzle{
+		Width: 2, Height: 1,
+		Dominoes: [][2]int{{1, 2}, {3, 4}},
+	}
+	if IsUnique(p) {
+		t.Fatal("IsUnique(p) = true, want false: puzzle has more than one distinct solution")
+	}
+}
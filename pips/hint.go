@@ -0,0 +1,102 @@
+package pips
+
+// HintKind classifies what FindHint discovered about a partially-played position.
+type HintKind int
+
+const (
+	// HintNextMove means the position is still solvable: Move is the next correct
+	// assignment toward completing it.
+	HintNextMove HintKind = iota
+	// HintDeadEnd means propagation alone already proves the position can't be
+	// completed: Square is the first square whose domain of remaining candidate
+	// values ran out.
+	HintDeadEnd
+	// HintUnsolvable means the backtracking search exhausted every remaining
+	// possibility without finding a solution, but no single square's domain gave
+	// it away - the contradiction only shows up once several squares are filled.
+	HintUnsolvable
+	// HintSolved means every square is already covered; there's nothing left to
+	// hint at.
+	HintSolved
+)
+
+// Hint is what FindHint reports about the current position.
+type Hint struct {
+	Kind HintKind
+
+	// Set when Kind is HintNextMove: the move that advances toward a solution.
+	Move *Move
+	// Set when Kind is HintDeadEnd: the square propagation proved unfillable.
+	Square *GridSquare
+}
+
+// FindHint looks at a partially-played position - grid and dominoes, with
+// moveQueue holding every move made on them so far - and reports what an
+// interactive player should do next: the next move toward a solution, the first
+// square propagation alone proves can't be filled, or that no solution is
+// reachable at all. It never leaves grid or dominoes changed: any search it runs
+// to find a next move is fully undone before it returns.
+func FindHint(grid [][]*GridSquare, dominoes DominoSet, moveQueue MoveQueue) Hint {
+	if pickEmptySquare(grid) == nil {
+		return Hint{Kind: HintSolved}
+	}
+
+	solver := NewSolver(grid, dominoes, true)
+	seedAssigned(solver, moveQueue)
+	if !solver.cp.Propagate() {
+		return Hint{Kind: HintDeadEnd, Square: firstEmptyDomainSquare(solver, grid)}
+	}
+
+	continuation := make(MoveQueue, 0)
+	solved := solver.Solve(grid, dominoes, &continuation)
+	var next *Move
+	if solved {
+		next = continuation[0]
+	}
+	for len(continuation) > 0 {
+		continuation.Pop()
+	}
+	if !solved {
+		return Hint{Kind: HintUnsolvable}
+	}
+	return Hint{Kind: HintNextMove, Move: next}
+}
+
+// seedAssigned folds every Assign move already in moveQueue into solver's fresh
+// propagation state, the same way captureSolution reads them back out of a move
+// queue: a domino's orientation never changes once it's assigned, so
+// Domino.NeighborCell is still accurate here. NewSolver has no way to know which
+// dominoes were already placed before it was built, so without this a freshly
+// built solver would wrongly treat every already-assigned square and domino as
+// still open.
+func seedAssigned(solver *Solver, moveQueue MoveQueue) {
+	for _, move := range moveQueue {
+		if move.Pruned || move.MoveType != MoveTypeAssign {
+			continue
+		}
+		cell1 := move.GridSquare.Cell
+		cell2, _ := move.Domino.NeighborCell(move.GridSquare)
+		solver.cp.AssignDomino(
+			solver.dominoIndex[move.Domino],
+			solver.squareIndexByCell[cell1], move.Domino.Square1Value,
+			solver.squareIndexByCell[cell2], move.Domino.Square2Value,
+		)
+	}
+}
+
+// firstEmptyDomainSquare returns the square behind solver's propagation failure:
+// the first square, in grid's own row-major order, whose domain ran out of
+// candidate values.
+func firstEmptyDomainSquare(solver *Solver, grid [][]*GridSquare) *GridSquare {
+	for _, row := range grid {
+		for _, sq := range row {
+			if sq == nil {
+				continue
+			}
+			if idx, ok := solver.squareIndexByCell[sq.Cell]; ok && solver.cp.SquareDomain(idx).Empty() {
+				return sq
+			}
+		}
+	}
+	return nil
+}
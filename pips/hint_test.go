@@ -0,0 +1,103 @@
+package pips
+
+import "testing"
+
+func TestFindHintNextMoveOnFreshBoard(t *testing.T) {
+	p := twoCellSumPuzzle()
+	grid, dominoes, err := BuildGrid(p)
+	if err != nil {
+		t.Fatalf("BuildGrid: %v", err)
+	}
+	moveQueue := make(MoveQueue, 0)
+
+	hint := FindHint(grid, dominoes, moveQueue)
+	if hint.Kind != HintNextMove {
+		t.Fatalf("FindHint on a fresh board returned Kind %v, want HintNextMove", hint.Kind)
+	}
+	if hint.Move == nil {
+		t.Fatal("FindHint returned a HintNextMove with a nil Move")
+	}
+
+	// FindHint must not leave the board changed by the search it ran to find that move.
+	for _, row := range grid {
+		for _, sq := range row {
+			if sq != nil && sq.DominoAssigned() {
+				t.Fatalf("FindHint left square (%d,%d) assigned; it should have undone its search", sq.X, sq.Y)
+			}
+		}
+	}
+}
+
+func TestFindHintSolvedWhenBoardAlreadyComplete(t *testing.T) {
+	p := twoCellSumPuzzle()
+	grid, dominoes, err := BuildGrid(p)
+	if err != nil {
+		t.Fatalf("BuildGrid: %v", err)
+	}
+	moveQueue := make(MoveQueue, 0)
+	if !moveQueue.TryPush(&Move{Domino: dominoes[0], GridSquare: grid[0][0], MoveType: MoveTypeAssign}) {
+		t.Fatal("setup: couldn't place the only domino")
+	}
+
+	hint := FindHint(grid, dominoes, moveQueue)
+	if hint.Kind != HintSolved {
+		t.Fatalf("FindHint on a complete board returned Kind %v, want HintSolved", hint.Kind)
+	}
+}
+
+func TestFindHintDeadEndWhenRemainderExceedsMaxPip(t *testing.T) {
+	// A 3-cell sum region spanning two dominoes: placing the first domino leaves
+	// the third cell needing a value above MaxPipValue, which no domino half can
+	// ever carry. That's provable by propagation alone, without trying the
+	// remaining domino at all.
+	p := &Puzzle{
+		Width: 4, Height: 1,
+		Regions: []RegionSpec{
+			{Type: RestrictionTypeSumsTo, Arg: 10, Cells: []Coord{{X: 0, Y: 0}, {X: 1, Y: 0}, {X: 2, Y: 0}}},
+		},
+		Dominoes: [][2]int{{1, 2}, {3, 4}},
+	}
+	grid, dominoes, err := BuildGrid(p)
+	if err != nil {
+		t.Fatalf("BuildGrid: %v", err)
+	}
+	moveQueue := make(MoveQueue, 0)
+	if !moveQueue.TryPush(&Move{Domino: dominoes[0], GridSquare: grid[0][0], MoveType: MoveTypeAssign}) {
+		t.Fatal("setup: couldn't place the 1-2 domino")
+	}
+
+	hint := FindHint(grid, dominoes, moveQueue)
+	if hint.Kind != HintDeadEnd {
+		t.Fatalf("FindHint returned Kind %v, want HintDeadEnd", hint.Kind)
+	}
+	if hint.Square == nil || hint.Square.X != 2 || hint.Square.Y != 0 {
+		t.Fatalf("FindHint blamed square %+v, want (2,0)", hint.Square)
+	}
+}
+
+// TestFindHintNextMoveRequiresBacktracking guards against the chunk0-1
+// propagation bug: this puzzle is genuinely solvable, but only by trying one
+// domino placement, failing, and backtracking to another. Before that fix,
+// FindHint's CP-backed solve would wrongly come back HintUnsolvable on it.
+func TestFindHintNextMoveRequiresBacktracking(t *testing.T) {
+	p := &Puzzle{
+		Width: 4, Height: 4,
+		Dominoes: [][2]int{{1, 1}, {2, 0}, {4, 4}, {0, 4}, {1, 1}, {0, 4}, {0, 2}, {1, 2}},
+		Regions: []RegionSpec{
+			{Type: RestrictionTypeLessThan, Arg: 3, Cells: []Coord{{X: 1, Y: 0}}},
+			{Type: RestrictionTypeGreaterThan, Arg: 1, Cells: []Coord{{X: 3, Y: 3}}},
+			{Type: RestrictionTypeGreaterThan, Arg: 3, Cells: []Coord{{X: 1, Y: 1}}},
+			{Type: RestrictionTypeLessThan, Arg: 1, Cells: []Coord{{X: 1, Y: 2}}},
+		},
+	}
+	grid, dominoes, err := BuildGrid(p)
+	if err != nil {
+		t.Fatalf("BuildGrid: %v", err)
+	}
+	moveQueue := make(MoveQueue, 0)
+
+	hint := FindHint(grid, dominoes, moveQueue)
+	if hint.Kind != HintNextMove {
+		t.Fatalf("FindHint on a solvable-only-via-backtracking position returned Kind %v, want HintNextMove", hint.Kind)
+	}
+}
@@ -1,24 +1,40 @@
-package main
+package pips
 
-import "fmt"
+import (
+	"fmt"
 
-// Gridsquare represents a single square on the grid and all the metadata associated with it, including its logical restrictions
+	"github.com/mmandrus/pips-solver/board"
+)
+
+// GridSquare is a handle onto one playable cell: the (x, y) coordinates callers
+// still address it by, plus the packed Cell index and the Board that now owns all
+// of its mutable state (occupancy, pip value, restriction). Restriction is only
+// used while the puzzle is being parsed, to describe what compileBoard should wire
+// up; once Board and Cell are set, Board is authoritative and Restriction is no
+// longer consulted.
 type GridSquare struct {
 	X int
 	Y int
 
-	DominoAssigned *Domino
-	PipValue       int
+	Cell  int
+	Board *board.Board
 
 	Restriction *Restriction
+}
+
+// DominoAssigned reports whether a domino currently covers this square.
+func (s *GridSquare) DominoAssigned() bool {
+	return s.Board.Occupied(s.Cell)
+}
 
-	TopNeighbor    *GridSquare
-	BottomNeighbor *GridSquare
-	LeftNeighbor   *GridSquare
-	RightNeighbor  *GridSquare
+// PipValue returns the pip value currently occupying this square, if any.
+func (s *GridSquare) PipValue() (int, bool) {
+	return s.Board.PipValue(s.Cell)
 }
 
-// Restriction represents a restriction on the grid. Each square maintains a list of its own restrictions
+// Restriction describes a restriction on the grid, as parsed from user input.
+// compileBoard turns these into board.RegionState entries; the live Arg and
+// NumSquaresLeft tracked during solving live on the Board from then on.
 type Restriction struct {
 	Type RestrictionType
 	// gt/lt: target value
@@ -29,28 +45,6 @@ type Restriction struct {
 	NumSquaresLeft int
 }
 
-func (r *Restriction) Check(value int, numSquares int) bool {
-	switch r.Type {
-	case RestrictionTypeNone:
-		return true
-	case RestrictionTypeGreaterThan:
-		return value > r.Arg
-	case RestrictionTypeLessThan:
-		return value < r.Arg
-	case RestrictionTypeEqual:
-		return r.Arg == -1 || value == r.Arg
-	case RestrictionTypeSumsTo:
-		if r.Arg-value < 0 {
-			return false
-		}
-		if r.NumSquaresLeft == numSquares && value != r.Arg {
-			return false
-		}
-		return true
-	}
-	return false
-}
-
 type RestrictionType string
 
 const (
@@ -61,6 +55,20 @@ const (
 	RestrictionTypeSumsTo      RestrictionType = "sum"
 )
 
+func boardRegionType(t RestrictionType) board.RegionType {
+	switch t {
+	case RestrictionTypeGreaterThan:
+		return board.RegionGreaterThan
+	case RestrictionTypeLessThan:
+		return board.RegionLessThan
+	case RestrictionTypeEqual:
+		return board.RegionEqual
+	case RestrictionTypeSumsTo:
+		return board.RegionSum
+	}
+	return board.RegionNone
+}
+
 // Domino represents a single domino and all the metadata associated with it, including its value and its rotation
 // A domino has no awareness of where it sits on the grid, just its orientation and whether it is available for assignment
 type Domino struct {
@@ -76,6 +84,21 @@ func (d *Domino) GetRotation() int {
 	return d.rotation % 4
 }
 
+// orientation maps the domino's current rotation onto the board package's
+// direction enum.
+func (d *Domino) orientation() board.Orientation {
+	switch d.GetRotation() {
+	case 0: // horizontal going to right (starting state)
+		return board.OrientRight
+	case 1: // vertical going down
+		return board.OrientDown
+	case 2: // horizontal going to left
+		return board.OrientLeft
+	default: // vertical going up
+		return board.OrientUp
+	}
+}
+
 // Possible move
 func (d *Domino) Rotate90DegreesClockwise() {
 	d.rotation++
@@ -91,102 +114,32 @@ func (d *Domino) Swap() {
 	d.Square1Value, d.Square2Value = d.Square2Value, d.Square1Value
 }
 
-// Possible move, returns its own undo action depending on what was done during placement
-func (d *Domino) Assign(s *GridSquare, neighbor *GridSquare) func() {
-	s.DominoAssigned = d
-	neighbor.DominoAssigned = d
-	wasBlank, neighborWasBlank := false, false
-	if s.Restriction.Type == RestrictionTypeEqual {
-		if s.Restriction.Arg == -1 {
-			s.Restriction.Arg = d.Square1Value
-			wasBlank = true
-		}
-	}
-	if neighbor.Restriction.Type == RestrictionTypeEqual {
-		if neighbor.Restriction.Arg == -1 {
-			neighbor.Restriction.Arg = d.Square2Value
-			neighborWasBlank = true
-		}
-	}
-	if s.Restriction.Type == RestrictionTypeSumsTo {
-		s.Restriction.Arg -= d.Square1Value
-		s.Restriction.NumSquaresLeft--
-	}
-	if neighbor.Restriction.Type == RestrictionTypeSumsTo {
-		neighbor.Restriction.Arg -= d.Square2Value
-		neighbor.Restriction.NumSquaresLeft--
-	}
-
-	d.IsAssigned = true
-
-	undoFunc := func() {
-		s.DominoAssigned = nil
-		d.IsAssigned = false
-		neighbor.DominoAssigned = nil
-		if wasBlank {
-			s.Restriction.Arg = -1
-		}
-		if neighborWasBlank {
-			neighbor.Restriction.Arg = -1
-		}
-		if s.Restriction.Type == RestrictionTypeSumsTo {
-			s.Restriction.Arg += d.Square1Value
-			s.Restriction.NumSquaresLeft++
-		}
-		if neighbor.Restriction.Type == RestrictionTypeSumsTo {
-			neighbor.Restriction.Arg += d.Square2Value
-			neighbor.Restriction.NumSquaresLeft++
-		}
-	}
-
-	return undoFunc
+// NeighborCell returns the board cell index this domino would occupy as its second
+// half if assigned to s at its current rotation, or false if that would go off the
+// edge of the board.
+func (d *Domino) NeighborCell(s *GridSquare) (int, bool) {
+	return s.Board.Neighbor(s.Cell, d.orientation())
 }
 
 func (d *Domino) TryAssign(s *GridSquare) (bool, func()) {
-	// Fail-fast: ensure that the domino doesn't overlap with a placed domino on an adjacent square or go out of bounds
-	var neighbor *GridSquare
-	switch d.GetRotation() {
-	case 0: // horizontal going to right (starting state)
-		neighbor = s.RightNeighbor
-	case 1: // vertical going down
-		neighbor = s.BottomNeighbor
-	case 2: // horizontal going to left
-		neighbor = s.LeftNeighbor
-	case 3: // vertical going up
-		neighbor = s.TopNeighbor
-	}
-	if neighbor == nil {
+	// Fail-fast: a single shift-and-AND against the occupancy plane tells us
+	// whether this placement would overlap an assigned domino or go out of bounds.
+	neighbor, ok := s.Board.CanOccupy(s.Cell, d.orientation())
+	if !ok {
 		return false, nil
 	}
-	if neighbor.DominoAssigned != nil {
+
+	if !s.Board.CanPlace(s.Cell, neighbor, d.Square1Value, d.Square2Value) {
 		return false, nil
 	}
 
-	// We now know the two squares that placing this domino would affect, check both of their restrictions
-	// If they are the same restriction, we can check this easily in one operation
-	if s.Restriction == neighbor.Restriction {
-		if s.Restriction.Type == RestrictionTypeEqual {
-			if d.Square1Value != d.Square1Value {
-				return false, nil
-			}
-			if !s.Restriction.Check(d.Square1Value, 1) {
-				return false, nil
-			}
-		}
-		if s.Restriction.Type == RestrictionTypeSumsTo {
-			sumOfDomino := d.Square1Value + d.Square2Value
-			if !s.Restriction.Check(sumOfDomino, 2) {
-				return false, nil
-			}
-		}
-	} else {
-		// Only check neighbor, we already checked the current square during candidate selection
-		if !neighbor.Restriction.Check(d.Square2Value, 1) {
-			return false, nil
-		}
-	}
+	undoBoard := s.Board.Assign(s.Cell, neighbor, d.Square1Value, d.Square2Value)
+	d.IsAssigned = true
 
-	undoFunc := d.Assign(s, neighbor)
+	undoFunc := func() {
+		d.IsAssigned = false
+		undoBoard()
+	}
 
 	return true, undoFunc
 }
@@ -295,8 +248,8 @@ func (ds *DominoSet) FindAvailableCandidates(square *GridSquare) []*DominoCandid
 			continue
 		}
 
-		leftMatch := square.Restriction.Check(domino.Square1Value, 1)
-		rightMatch := square.Restriction.Check(domino.Square2Value, 1)
+		leftMatch := square.Board.CheckValue(square.Cell, domino.Square1Value)
+		rightMatch := square.Board.CheckValue(square.Cell, domino.Square2Value)
 		if !leftMatch && !rightMatch {
 			continue
 		}
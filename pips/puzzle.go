@@ -0,0 +1,233 @@
+package pips
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/mmandrus/pips-solver/board"
+)
+
+// Puzzle is the declarative, file-based description of a pips board: its
+// dimensions, which cells are blank, the restricted regions, and the domino
+// multiset. It's the JSON shape ParsePuzzle reads and the one BuildGrid compiles
+// into a solvable grid.
+type Puzzle struct {
+	Width  int `json:"width"`
+	Height int `json:"height"`
+
+	// Blanks lists the non-playable cells; every other cell in the width x height
+	// grid is playable.
+	Blanks []Coord `json:"blanks,omitempty"`
+
+	Regions []RegionSpec `json:"regions,omitempty"`
+
+	// Dominoes is the domino multiset available to place, each as [value1, value2].
+	Dominoes [][2]int `json:"dominoes"`
+}
+
+// Coord is a 0-indexed (x, y) grid coordinate.
+type Coord struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+}
+
+// RegionSpec describes one restricted region: its type, the argument that type
+// takes (ignored for "eq"), and the cells it covers.
+type RegionSpec struct {
+	Type  RestrictionType `json:"type"`
+	Arg   int             `json:"arg,omitempty"`
+	Cells []Coord         `json:"cells"`
+}
+
+// ParsePuzzle reads a Puzzle from its JSON encoding. YAML puzzle files aren't
+// supported yet.
+func ParsePuzzle(data []byte) (*Puzzle, error) {
+	var p Puzzle
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("pips: parsing puzzle: %w", err)
+	}
+	return &p, nil
+}
+
+// BuildGrid compiles p into the grid and domino set the solver operates on, with
+// the grid's Board already finalized via CompileBoard. It returns an error if any
+// blank or region cell falls outside p's Width x Height, the same bound check
+// Verify's valueAt already does for assignment values - without it, a malformed or
+// hand-edited puzzle file panics deep in the grid construction below instead of
+// failing cleanly.
+func BuildGrid(p *Puzzle) ([][]*GridSquare, DominoSet, error) {
+	inBounds := func(c Coord) bool {
+		return c.X >= 0 && c.X < p.Width && c.Y >= 0 && c.Y < p.Height
+	}
+	for _, c := range p.Blanks {
+		if !inBounds(c) {
+			return nil, nil, fmt.Errorf("pips: blank cell (%d,%d) is outside the %dx%d board", c.X, c.Y, p.Width, p.Height)
+		}
+	}
+	for _, region := range p.Regions {
+		for _, c := range region.Cells {
+			if !inBounds(c) {
+				return nil, nil, fmt.Errorf("pips: region cell (%d,%d) is outside the %dx%d board", c.X, c.Y, p.Width, p.Height)
+			}
+		}
+	}
+
+	blank := make(map[Coord]bool, len(p.Blanks))
+	for _, c := range p.Blanks {
+		blank[c] = true
+	}
+
+	grid := make([][]*GridSquare, p.Height)
+	for y := 0; y < p.Height; y++ {
+		grid[y] = make([]*GridSquare, p.Width)
+		for x := 0; x < p.Width; x++ {
+			if blank[Coord{X: x, Y: y}] {
+				continue
+			}
+			grid[y][x] = &GridSquare{X: x, Y: y, Restriction: &Restriction{Type: RestrictionTypeNone}}
+		}
+	}
+
+	for _, region := range p.Regions {
+		arg := region.Arg
+		if region.Type == RestrictionTypeEqual {
+			// eq's Arg is blank until the first domino assignment fills it in; the
+			// file format's Arg field is ignored for this type, so don't let a
+			// zero-value JSON field masquerade as "every cell must equal 0".
+			arg = -1
+		}
+		restriction := &Restriction{Type: region.Type, Arg: arg}
+		for _, c := range region.Cells {
+			grid[c.Y][c.X].Restriction = restriction
+			restriction.NumSquaresLeft++
+		}
+	}
+
+	CompileBoard(grid)
+
+	dominoes := make(DominoSet, len(p.Dominoes))
+	for i, d := range p.Dominoes {
+		dominoes[i] = &Domino{Square1Value: d[0], Square2Value: d[1]}
+	}
+	return grid, dominoes, nil
+}
+
+// CompileBoard packs grid's squares and restrictions into a board.Board: every
+// non-nil square gets a stable cell index and its neighbors precomputed, and
+// squares sharing a *Restriction become one board region. Must run after every
+// restricted region has been assigned; it sets each GridSquare's Cell and Board
+// fields in place.
+func CompileBoard(grid [][]*GridSquare) *board.Board {
+	height := len(grid)
+	width := 0
+	if height > 0 {
+		width = len(grid[0])
+	}
+	b := board.New(width, height)
+
+	for y, row := range grid {
+		for x, sq := range row {
+			if sq != nil {
+				b.SetPlayable(x, y)
+			}
+		}
+	}
+	b.Finalize()
+
+	regionOf := make(map[*Restriction]int)
+	for _, row := range grid {
+		for _, sq := range row {
+			if sq == nil {
+				continue
+			}
+			sq.Cell = b.CellIndex(sq.X, sq.Y)
+			sq.Board = b
+
+			if sq.Restriction == nil || sq.Restriction.Type == RestrictionTypeNone {
+				continue
+			}
+			regionID, ok := regionOf[sq.Restriction]
+			if !ok {
+				regionID = b.AddRegion(boardRegionType(sq.Restriction.Type), sq.Restriction.Arg)
+				regionOf[sq.Restriction] = regionID
+			}
+			b.AddCellToRegion(sq.Cell, regionID)
+		}
+	}
+
+	return b
+}
+
+// Assignment is a proposed pip-value grid for a puzzle, as read from a solution
+// file passed to Verify. Values[y][x] is the pip value assigned to that cell, or
+// -1 for a cell the proposal leaves blank.
+type Assignment struct {
+	Values [][]int `json:"values"`
+}
+
+// Verify reports whether assignment satisfies every restricted region in p. It
+// only checks the restrictions, not that the values came from a legal domino
+// placement - pair it with BuildGrid and the solver's CanPlace logic for that.
+func Verify(p *Puzzle, a *Assignment) (bool, error) {
+	valueAt := func(c Coord) (int, error) {
+		if c.Y < 0 || c.Y >= len(a.Values) || c.X < 0 || c.X >= len(a.Values[c.Y]) {
+			return 0, fmt.Errorf("pips: assignment has no value at (%d,%d)", c.X, c.Y)
+		}
+		v := a.Values[c.Y][c.X]
+		if v < 0 || v > 6 {
+			return 0, fmt.Errorf("pips: value %d at (%d,%d) is out of range", v, c.X, c.Y)
+		}
+		return v, nil
+	}
+
+	for _, region := range p.Regions {
+		switch region.Type {
+		case RestrictionTypeGreaterThan:
+			for _, c := range region.Cells {
+				v, err := valueAt(c)
+				if err != nil {
+					return false, err
+				}
+				if v <= region.Arg {
+					return false, nil
+				}
+			}
+		case RestrictionTypeLessThan:
+			for _, c := range region.Cells {
+				v, err := valueAt(c)
+				if err != nil {
+					return false, err
+				}
+				if v >= region.Arg {
+					return false, nil
+				}
+			}
+		case RestrictionTypeEqual:
+			want := -1
+			for _, c := range region.Cells {
+				v, err := valueAt(c)
+				if err != nil {
+					return false, err
+				}
+				if want == -1 {
+					want = v
+				} else if v != want {
+					return false, nil
+				}
+			}
+		case RestrictionTypeSumsTo:
+			total := 0
+			for _, c := range region.Cells {
+				v, err := valueAt(c)
+				if err != nil {
+					return false, err
+				}
+				total += v
+			}
+			if total != region.Arg {
+				return false, nil
+			}
+		}
+	}
+	return true, nil
+}
@@ -0,0 +1,19 @@
+package pips
+
+// Solve builds puzzle's grid and domino set and runs the constraint-propagation
+// backtracker to completion, returning the winning move sequence. This is the
+// same search the interactive CLI flow has always used; it's exposed here so
+// other Go programs can solve a Puzzle without going through the CLI at all.
+// Returns an error if p itself is malformed (see BuildGrid), without attempting
+// to solve it.
+func Solve(p *Puzzle) (MoveQueue, bool, error) {
+	grid, dominoes, err := BuildGrid(p)
+	if err != nil {
+		return nil, false, err
+	}
+	moveQueue := make(MoveQueue, 0)
+	solver := NewSolver(grid, dominoes, true)
+	ok := solver.Solve(grid, dominoes, &moveQueue)
+	return moveQueue, ok, nil
+}
+
@@ -0,0 +1,24 @@
+package pips
+
+import "testing"
+
+// TestSolveRejectsGreaterThanViolationOnSharedRegion guards against a bug where a
+// gt/lt region spanning both cells of a domino never actually got checked: CanPlace
+// fell through to an unconditional "true" for gt/lt when the two cells shared a
+// region, so Solve could report success with a placement Verify would reject.
+// Domino 1-5 has no orientation where both halves exceed 3, so this must be
+// unsolvable.
+func TestSolveRejectsGreaterThanViolationOnSharedRegion(t *testing.T) {
+	p := &Puzzle{
+		Width: 2, Height: 1,
+		Dominoes: [][2]int{{1, 5}},
+		Regions:  []RegionSpec{{Type: RestrictionTypeGreaterThan, Arg: 3, Cells: []Coord{{X: 0, Y: 0}, {X: 1, Y: 0}}}},
+	}
+	_, ok, err := Solve(p)
+	if err != nil {
+		t.Fatalf("Solve returned error: %v", err)
+	}
+	if ok {
+		t.Fatal("Solve(p) = true, want false: no orientation of domino 1-5 satisfies gt:3 on both cells")
+	}
+}
@@ -0,0 +1,270 @@
+package pips
+
+import (
+	"fmt"
+
+	"github.com/mmandrus/pips-solver/board"
+	"github.com/mmandrus/pips-solver/propagate"
+)
+
+// Solver drives the backtracking search over a grid and domino set. With EnableCP
+// set, it narrows each square's domain and each domino's candidate placements to a
+// fixpoint before recursing and after every assignment, so hopeless branches are
+// abandoned without ever being searched. With EnableCP unset it behaves exactly like
+// the original naive backtracker, which is kept around for benchmarking against.
+type Solver struct {
+	EnableCP bool
+
+	cp                *propagate.State
+	board             *board.Board
+	squareIndexByCell map[int]int
+	dominoIndex       map[*Domino]int
+	regions           []*propagate.Region
+	boardRegionIDs    []int
+}
+
+// NewSolver builds a Solver for the given grid and domino set. When enableCP is
+// true it also builds the propagation state the solve loop will keep in sync with
+// the board as it searches. The grid's squares must already have been compiled
+// into a board.Board (see compileBoard).
+func NewSolver(grid [][]*GridSquare, dominoes DominoSet, enableCP bool) *Solver {
+	s := &Solver{EnableCP: enableCP}
+	if !enableCP {
+		return s
+	}
+
+	var squares []*GridSquare
+	s.squareIndexByCell = make(map[int]int)
+	for _, row := range grid {
+		for _, sq := range row {
+			if sq == nil {
+				continue
+			}
+			s.board = sq.Board
+			s.squareIndexByCell[sq.Cell] = len(squares)
+			squares = append(squares, sq)
+		}
+	}
+
+	var pairs []propagate.Pair
+	for _, sq := range squares {
+		if nb, ok := sq.Board.Neighbor(sq.Cell, board.OrientRight); ok {
+			pairs = append(pairs, propagate.Pair{A: s.squareIndexByCell[sq.Cell], B: s.squareIndexByCell[nb]})
+		}
+		if nb, ok := sq.Board.Neighbor(sq.Cell, board.OrientDown); ok {
+			pairs = append(pairs, propagate.Pair{A: s.squareIndexByCell[sq.Cell], B: s.squareIndexByCell[nb]})
+		}
+	}
+
+	s.dominoIndex = make(map[*Domino]int, len(dominoes))
+	dominoValues := make([][2]int, len(dominoes))
+	for i, d := range dominoes {
+		s.dominoIndex[d] = i
+		dominoValues[i] = [2]int{d.Square1Value, d.Square2Value}
+	}
+
+	// Squares that share a board region ID belong to the same region; build one
+	// propagate.Region per distinct board region ID and keep the ID alongside it so
+	// syncRegions can pull its latest Arg/NumSquaresLeft each time.
+	regionOf := make(map[int]*propagate.Region)
+	for _, sq := range squares {
+		id := sq.Board.RegionOf(sq.Cell)
+		if id == -1 {
+			continue
+		}
+		region, ok := regionOf[id]
+		if !ok {
+			region = &propagate.Region{Type: boardToPropagateRegionType(sq.Board.Region(id).Type)}
+			regionOf[id] = region
+			s.regions = append(s.regions, region)
+			s.boardRegionIDs = append(s.boardRegionIDs, id)
+		}
+		region.Squares = append(region.Squares, s.squareIndexByCell[sq.Cell])
+	}
+
+	s.cp = propagate.NewState(len(squares), s.regions, pairs, dominoValues)
+	s.syncRegions()
+	return s
+}
+
+// syncRegions copies each board region's live Arg/NumSquaresLeft into its matching
+// propagate.Region. Board.Assign mutates board regions directly, so this is what
+// keeps the propagation state honest after every move.
+func (s *Solver) syncRegions() {
+	for i, region := range s.regions {
+		bs := s.board.Region(s.boardRegionIDs[i])
+		region.Arg = bs.Arg
+		region.NumSquaresLeft = bs.NumSquaresLeft
+	}
+}
+
+func boardToPropagateRegionType(t board.RegionType) propagate.RegionType {
+	switch t {
+	case board.RegionGreaterThan:
+		return propagate.RegionGreaterThan
+	case board.RegionLessThan:
+		return propagate.RegionLessThan
+	case board.RegionEqual:
+		return propagate.RegionEqual
+	case board.RegionSum:
+		return propagate.RegionSum
+	}
+	return propagate.RegionNone
+}
+
+// Solve runs the backtracking search to completion, leaving moveQueue holding the
+// full winning move sequence if it returns true.
+func (s *Solver) Solve(grid [][]*GridSquare, dominoes DominoSet, moveQueue *MoveQueue) bool {
+	if s.EnableCP && !s.cp.Propagate() {
+		return false
+	}
+	return s.makeNextMove(grid, dominoes, moveQueue, pickEmptySquare(grid))
+}
+
+func (s *Solver) makeNextMove(grid [][]*GridSquare, dominoes DominoSet, moveQueue *MoveQueue, emptySquare *GridSquare) (success bool) {
+	if emptySquare == nil {
+		// success condition: every square is covered
+		return true
+	}
+
+	// Get candidate dominos for this square.
+	candidates := dominoes.FindAvailableCandidates(emptySquare)
+	if len(candidates) == 0 {
+		if len(dominoes) == 0 {
+			// success condition: we have assigned all dominos
+			return true
+		}
+		// failure condition: none of the dominos we have left can satisfy the puzzle
+		return false
+	}
+
+	// Check candidate dominos in all possible orientations until we find one that we can assign
+	for _, candidate := range candidates {
+		numIterations := 8
+		if !candidate.isRightMatch {
+			// In this instance, we can skip half the combos immediately
+			numIterations /= 2
+		}
+		if !candidate.isLeftMatch {
+			// In this instance, we can skip half the combos immediately and go right for the swap
+			numIterations /= 2
+			moveQueue.TryPush(&Move{
+				Label:      fmt.Sprintf("Swap domino %d-%d", candidate.Domino.Square1Value, candidate.Domino.Square2Value),
+				Domino:     candidate.Domino,
+				GridSquare: emptySquare,
+				MoveType:   MoveTypeSwap,
+			})
+			defer func() {
+				if !success {
+					moveQueue.Pop()
+				}
+			}()
+		}
+		for i := 0; i < numIterations; i++ {
+			move := &Move{
+				Label:      fmt.Sprintf("Assign domino %d-%d to square %d,%d", candidate.Domino.Square1Value, candidate.Domino.Square2Value, emptySquare.X+1, emptySquare.Y+1),
+				Domino:     candidate.Domino,
+				GridSquare: emptySquare,
+				MoveType:   MoveTypeAssign,
+			}
+			// We were able to place this domino in its current state, try to make another move
+			if moveQueue.TryPush(move) {
+				cpOK := true
+				var cpSnapshot *propagate.Snapshot
+				if s.EnableCP {
+					// Snapshot before folding this assignment in, so a propagation
+					// failure (or a failed recursion below) can be undone cleanly,
+					// the same way moveQueue.Pop() undoes the grid-level Assign.
+					cpSnapshot = s.cp.Save()
+					neighborCell, _ := candidate.Domino.NeighborCell(emptySquare)
+					s.syncRegions()
+					s.cp.AssignDomino(
+						s.dominoIndex[candidate.Domino],
+						s.squareIndexByCell[emptySquare.Cell], candidate.Domino.Square1Value,
+						s.squareIndexByCell[neighborCell], candidate.Domino.Square2Value,
+					)
+					cpOK = s.cp.Propagate()
+				}
+				if cpOK {
+					if success := s.makeNextMove(grid, dominoes, moveQueue, pickEmptySquare(grid)); success {
+						// success condition: puzzle solved from this current state
+						return true
+					}
+				}
+				// failure condition: no valid next move can be made from this new state, undo the move and try the next candidate state
+				if s.EnableCP {
+					s.cp.Restore(cpSnapshot)
+				}
+				moveQueue.Pop()
+			}
+			// Try the next orientation
+			move = &Move{
+				Label:      fmt.Sprintf("Rotate domino %d-%d", candidate.Domino.Square1Value, candidate.Domino.Square2Value),
+				Domino:     candidate.Domino,
+				GridSquare: emptySquare,
+				MoveType:   MoveTypeRotate,
+			}
+			moveQueue.TryPush(move)
+			defer func() {
+				if !success {
+					moveQueue.Pop()
+				}
+			}()
+		}
+	}
+
+	return false
+}
+
+// liveRegion returns the live board.RegionState for square's region, if it has one.
+// Unlike square.Restriction (only accurate at parse time), this reflects every
+// Assign/undo that has happened on the board so far.
+func liveRegion(square *GridSquare) (*board.RegionState, bool) {
+	regionID := square.Board.RegionOf(square.Cell)
+	if regionID == -1 {
+		return nil, false
+	}
+	return square.Board.Region(regionID), true
+}
+
+// pickEmptySquare returns the GridSquare the solver should place its next domino on,
+// preferring the most constrained square it can find, or nil once every square is
+// covered.
+func pickEmptySquare(grid [][]*GridSquare) *GridSquare {
+	// find all blank squares
+	blankSquares := make([]*GridSquare, 0)
+	for y := 0; y < len(grid); y++ {
+		for x := 0; x < len(grid[y]); x++ {
+			if grid[y][x] == nil {
+				continue
+			}
+			if !grid[y][x].DominoAssigned() {
+				blankSquares = append(blankSquares, grid[y][x])
+			}
+		}
+	}
+	if len(blankSquares) == 0 {
+		return nil
+	}
+
+	// First try to find one with a single-square sum restriction
+	for _, square := range blankSquares {
+		if rs, ok := liveRegion(square); ok && rs.Type == board.RegionSum && rs.NumSquaresLeft == 1 {
+			return square
+		}
+	}
+	// If none exists, try to find an equal restriction that is already partially filled
+	for _, square := range blankSquares {
+		if rs, ok := liveRegion(square); ok && rs.Type == board.RegionEqual && rs.Arg != -1 {
+			return square
+		}
+	}
+	// Next, look for a gt or lt restriction
+	for _, square := range blankSquares {
+		if rs, ok := liveRegion(square); ok && (rs.Type == board.RegionGreaterThan || rs.Type == board.RegionLessThan) {
+			return square
+		}
+	}
+	// Give up on optimizing, just return the first blank square
+	return blankSquares[0]
+}
@@ -0,0 +1,306 @@
+package pips
+
+import (
+	"testing"
+
+	"github.com/mmandrus/pips-solver/board"
+)
+
+// benchPuzzle is one fixed-corpus puzzle: a rectangular grid (no blanks) split into
+// 2-cell sum regions, solved with a domino set sized to exactly tile it. Keeping the
+// corpus this simple means both backends are handed the identical search, so the
+// benchmark isolates the cost of the representation rather than the solve strategy.
+type benchPuzzle struct {
+	width, height int
+	// sums[i] is the target for the i'th horizontal domino-shaped pair, covering
+	// cells (2i, row) and (2i+1, row) in reading order.
+	sums     []int
+	dominoes [][2]int
+}
+
+var benchCorpus = []benchPuzzle{
+	{
+		width: 2, height: 2,
+		sums:     []int{3, 7},
+		dominoes: [][2]int{{1, 2}, {3, 4}},
+	},
+	{
+		width: 4, height: 2,
+		sums:     []int{3, 7, 5, 9},
+		dominoes: [][2]int{{1, 2}, {3, 4}, {2, 3}, {4, 5}},
+	},
+}
+
+// legacyGridSquare is a frozen copy of the pre-bitboard representation: a pointer
+// graph of neighbors and a shared *legacyRestriction per region. It exists only so
+// BenchmarkSolvePointerBackend and BenchmarkSolveBitboardBackendBare can compare the
+// two representations directly, not as a second production code path.
+type legacyGridSquare struct {
+	restriction *legacyRestriction
+	assigned    bool
+
+	right, down *legacyGridSquare
+}
+
+type legacyRestriction struct {
+	arg            int
+	numSquaresLeft int
+}
+
+func (r *legacyRestriction) check(value, numSquares int) bool {
+	if r.arg-value < 0 {
+		return false
+	}
+	if r.numSquaresLeft == numSquares && value != r.arg {
+		return false
+	}
+	return true
+}
+
+type legacyDomino struct {
+	v1, v2   int
+	assigned bool
+}
+
+func buildLegacyGrid(p benchPuzzle) ([]*legacyGridSquare, []*legacyDomino) {
+	squares := make([]*legacyGridSquare, p.width*p.height)
+	for i := range squares {
+		squares[i] = &legacyGridSquare{}
+	}
+	for y := 0; y < p.height; y++ {
+		for x := 0; x < p.width; x++ {
+			i := y*p.width + x
+			if x+1 < p.width {
+				squares[i].right = squares[i+1]
+			}
+			if y+1 < p.height {
+				squares[i].down = squares[i+(p.width)]
+			}
+		}
+	}
+	for i, sum := range p.sums {
+		r := &legacyRestriction{arg: sum, numSquaresLeft: 2}
+		squares[2*i].restriction = r
+		squares[2*i+1].restriction = r
+	}
+	dominoes := make([]*legacyDomino, len(p.dominoes))
+	for i, d := range p.dominoes {
+		dominoes[i] = &legacyDomino{v1: d[0], v2: d[1]}
+	}
+	return squares, dominoes
+}
+
+// legacySolve tries every unassigned domino, in both value orders, on the first
+// still-empty square, recursing until every domino is placed or every option is
+// exhausted - the same shape of search the original makeNextMove performed, just
+// walking pointers instead of a packed board.
+func legacySolve(squares []*legacyGridSquare, dominoes []*legacyDomino) bool {
+	var empty *legacyGridSquare
+	var emptyRight *legacyGridSquare
+	for _, sq := range squares {
+		if !sq.assigned {
+			empty = sq
+			emptyRight = sq.right
+			break
+		}
+	}
+	if empty == nil {
+		return true
+	}
+	if emptyRight == nil || emptyRight.assigned {
+		return false
+	}
+
+	for _, d := range dominoes {
+		if d.assigned {
+			continue
+		}
+		for _, values := range [][2]int{{d.v1, d.v2}, {d.v2, d.v1}} {
+			v1, v2 := values[0], values[1]
+			if empty.restriction != nil && !empty.restriction.check(v1, 1) {
+				continue
+			}
+			if emptyRight.restriction == empty.restriction {
+				if empty.restriction != nil && !empty.restriction.check(v1+v2, 2) {
+					continue
+				}
+			} else if emptyRight.restriction != nil && !emptyRight.restriction.check(v2, 1) {
+				continue
+			}
+
+			empty.assigned, emptyRight.assigned, d.assigned = true, true, true
+			if empty.restriction != nil {
+				empty.restriction.arg -= v1
+				empty.restriction.numSquaresLeft--
+			}
+			if emptyRight.restriction != nil && emptyRight.restriction != empty.restriction {
+				emptyRight.restriction.arg -= v2
+				emptyRight.restriction.numSquaresLeft--
+			}
+
+			if legacySolve(squares, dominoes) {
+				return true
+			}
+
+			empty.assigned, emptyRight.assigned, d.assigned = false, false, false
+			if empty.restriction != nil {
+				empty.restriction.arg += v1
+				empty.restriction.numSquaresLeft++
+			}
+			if emptyRight.restriction != nil && emptyRight.restriction != empty.restriction {
+				emptyRight.restriction.arg += v2
+				emptyRight.restriction.numSquaresLeft++
+			}
+		}
+	}
+	return false
+}
+
+// bitboardDomino is the bare-board counterpart to legacyDomino: just the two
+// values and whether it's placed, with no Move/Label machinery attached.
+type bitboardDomino struct {
+	v1, v2   int
+	assigned bool
+}
+
+// buildBareBoard packs p straight into a *board.Board and a []*bitboardDomino,
+// bypassing GridSquare, DominoSet, and CompileBoard entirely - there's no
+// production code path that builds a board this directly, but it's what lets
+// bitboardSolve touch nothing but board.Board's own primitives.
+func buildBareBoard(p benchPuzzle) (*board.Board, []*bitboardDomino) {
+	b := board.New(p.width, p.height)
+	for y := 0; y < p.height; y++ {
+		for x := 0; x < p.width; x++ {
+			b.SetPlayable(x, y)
+		}
+	}
+	for i, sum := range p.sums {
+		row, col := (2*i)/p.width, (2*i)%p.width
+		regionID := b.AddRegion(board.RegionSum, sum)
+		b.AddCellToRegion(b.CellIndex(col, row), regionID)
+		b.AddCellToRegion(b.CellIndex(col+1, row), regionID)
+	}
+	b.Finalize()
+
+	dominoes := make([]*bitboardDomino, len(p.dominoes))
+	for i, d := range p.dominoes {
+		dominoes[i] = &bitboardDomino{v1: d[0], v2: d[1]}
+	}
+	return b, dominoes
+}
+
+// bitboardSolve is bitboardSolve's mirror of legacySolve: it tries every
+// unassigned domino, in both value orders, on the first still-empty cell's
+// horizontal pair, recursing until every domino is placed or every option is
+// exhausted. Unlike BenchmarkSolveBitboardBackend, it calls only board.Board's
+// own methods directly - no Move structs, no Sprintf'd labels, no MoveQueue -
+// so the only thing that differs from legacySolve is the representation
+// underneath, the same way the two are meant to be compared.
+func bitboardSolve(b *board.Board, dominoes []*bitboardDomino, numCells int) bool {
+	empty := -1
+	for cell := 0; cell < numCells; cell++ {
+		if !b.Occupied(cell) {
+			empty = cell
+			break
+		}
+	}
+	if empty == -1 {
+		return true
+	}
+	neighbor, ok := b.CanOccupy(empty, board.OrientRight)
+	if !ok {
+		return false
+	}
+
+	for _, d := range dominoes {
+		if d.assigned {
+			continue
+		}
+		for _, values := range [][2]int{{d.v1, d.v2}, {d.v2, d.v1}} {
+			v1, v2 := values[0], values[1]
+			if !b.CheckValue(empty, v1) || !b.CanPlace(empty, neighbor, v1, v2) {
+				continue
+			}
+
+			undo := b.Assign(empty, neighbor, v1, v2)
+			d.assigned = true
+
+			if bitboardSolve(b, dominoes, numCells) {
+				return true
+			}
+
+			d.assigned = false
+			undo()
+		}
+	}
+	return false
+}
+
+func buildBitboardGrid(p benchPuzzle) ([][]*GridSquare, DominoSet) {
+	grid := make([][]*GridSquare, p.height)
+	for y := 0; y < p.height; y++ {
+		grid[y] = make([]*GridSquare, p.width)
+		for x := 0; x < p.width; x++ {
+			grid[y][x] = &GridSquare{X: x, Y: y, Restriction: &Restriction{Type: RestrictionTypeNone}}
+		}
+	}
+	for i, sum := range p.sums {
+		row, col := (2*i)/p.width, (2*i)%p.width
+		r := &Restriction{Type: RestrictionTypeSumsTo, Arg: sum}
+		grid[row][col].Restriction = r
+		grid[row][col+1].Restriction = r
+	}
+	CompileBoard(grid)
+	dominoes := make(DominoSet, len(p.dominoes))
+	for i, d := range p.dominoes {
+		dominoes[i] = &Domino{Square1Value: d[0], Square2Value: d[1]}
+	}
+	return grid, dominoes
+}
+
+func BenchmarkSolvePointerBackend(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		for _, p := range benchCorpus {
+			squares, dominoes := buildLegacyGrid(p)
+			if !legacySolve(squares, dominoes) {
+				b.Fatalf("legacy backend failed to solve fixed corpus puzzle %+v", p)
+			}
+		}
+	}
+}
+
+// BenchmarkSolveBitboardBackendBare is the fair comparison against
+// BenchmarkSolvePointerBackend: both call a bare recursive backtracker against
+// their own representation, with no Move/label/MoveQueue overhead on either
+// side. Representation is the only thing that differs between the two.
+func BenchmarkSolveBitboardBackendBare(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		for _, p := range benchCorpus {
+			bb, dominoes := buildBareBoard(p)
+			if !bitboardSolve(bb, dominoes, p.width*p.height) {
+				b.Fatalf("bare bitboard backend failed to solve fixed corpus puzzle %+v", p)
+			}
+		}
+	}
+}
+
+// BenchmarkSolveBitboardBackend runs the same corpus through the production
+// solver path (Solver.Solve, via makeNextMove's Move/MoveQueue machinery). It's
+// useful for tracking real end-to-end solve performance, but it is NOT a
+// representation-only comparison against BenchmarkSolvePointerBackend: it pays
+// per-candidate Move allocation and Sprintf'd labels that legacySolve never
+// does, so a gap here reflects that production overhead at least as much as it
+// reflects bitboard vs. pointer-graph cost. BenchmarkSolveBitboardBackendBare is
+// the one that isolates representation.
+func BenchmarkSolveBitboardBackend(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		for _, p := range benchCorpus {
+			grid, dominoes := buildBitboardGrid(p)
+			moveQueue := make(MoveQueue, 0)
+			solver := NewSolver(grid, dominoes, false)
+			if !solver.Solve(grid, dominoes, &moveQueue) {
+				b.Fatalf("bitboard backend failed to solve fixed corpus puzzle %+v", p)
+			}
+		}
+	}
+}
@@ -0,0 +1,409 @@
+// Package propagate implements Sudoku-style constraint propagation for the pips
+// solver. It keeps, for every empty square, a domain of still-possible pip values,
+// and for every unassigned domino, the set of square-pairs it could still occupy.
+// Running Propagate to a fixpoint before the backtracker recurses lets it notice
+// dead branches (an empty domain) without ever trying them.
+package propagate
+
+import "sort"
+
+// Domain is a bitmask over pip values 0-6: bit v is set when value v is still possible.
+type Domain uint8
+
+// MaxPipValue is the highest pip value a domino half can carry.
+const MaxPipValue = 6
+
+// FullDomain has every value from 0 to MaxPipValue set.
+const FullDomain Domain = 1<<(MaxPipValue+1) - 1
+
+// Has reports whether v is still in the domain.
+func (d Domain) Has(v int) bool {
+	return d&(1<<uint(v)) != 0
+}
+
+// Remove clears v from the domain.
+func (d Domain) Remove(v int) Domain {
+	return d &^ (1 << uint(v))
+}
+
+// Empty reports whether no values remain.
+func (d Domain) Empty() bool {
+	return d == 0
+}
+
+// Single reports whether exactly one value remains, returning it.
+func (d Domain) Single() (int, bool) {
+	if d == 0 || d&(d-1) != 0 {
+		return 0, false
+	}
+	for v := 0; v <= MaxPipValue; v++ {
+		if d.Has(v) {
+			return v, true
+		}
+	}
+	return 0, false
+}
+
+// RegionType mirrors the restriction kinds a square can belong to.
+type RegionType int
+
+const (
+	RegionNone RegionType = iota
+	RegionGreaterThan
+	RegionLessThan
+	RegionEqual
+	RegionSum
+)
+
+// Region is one restricted area of the board, described abstractly enough that this
+// package never has to know about GridSquare or Restriction directly.
+type Region struct {
+	Type RegionType
+	// Arg is the gt/lt/eq target, or the remaining sum still owed for a sum region.
+	// -1 means "unknown yet" (an eq region with no square assigned).
+	Arg int
+	// NumSquaresLeft is how many squares in this region are still unassigned.
+	NumSquaresLeft int
+	// Squares lists the square indices that belong to this region.
+	Squares []int
+}
+
+// Pair is an unordered pair of adjacent square indices that a domino could occupy.
+// A is always the smaller index.
+type Pair struct {
+	A, B int
+}
+
+// State is the full propagation state for one board position.
+type State struct {
+	squareDomains []Domain
+	squareRegion  []int // region index per square, or -1
+	regions       []*Region
+
+	pairs          []Pair
+	dominoValues   [][2]int
+	dominoAssigned []bool
+	// dominoPairs[d] holds the indices into pairs that domino d could still occupy.
+	dominoPairs [][]int
+}
+
+// NewState builds a propagation state for numSquares squares and the given regions
+// and dominoes. pairs enumerates every adjacent square-pair a domino might occupy;
+// dominoValues holds each domino's two pip values in domino-index order.
+func NewState(numSquares int, regions []*Region, pairs []Pair, dominoValues [][2]int) *State {
+	s := &State{
+		squareDomains:  make([]Domain, numSquares),
+		squareRegion:   make([]int, numSquares),
+		regions:        regions,
+		pairs:          pairs,
+		dominoValues:   dominoValues,
+		dominoAssigned: make([]bool, len(dominoValues)),
+		dominoPairs:    make([][]int, len(dominoValues)),
+	}
+	for i := range s.squareDomains {
+		s.squareDomains[i] = FullDomain
+		s.squareRegion[i] = -1
+	}
+	for ri, r := range regions {
+		for _, sq := range r.Squares {
+			s.squareRegion[sq] = ri
+		}
+	}
+	allPairIdx := make([]int, len(pairs))
+	for i := range pairs {
+		allPairIdx[i] = i
+	}
+	for d := range dominoValues {
+		s.dominoPairs[d] = append([]int(nil), allPairIdx...)
+	}
+	return s
+}
+
+// SquareDomain returns the current domain of square index i.
+func (s *State) SquareDomain(i int) Domain {
+	return s.squareDomains[i]
+}
+
+// Snapshot captures everything Propagate and AssignDomino mutate, so a failed
+// branch can be undone with Restore instead of having to be rebuilt from
+// scratch.
+type Snapshot struct {
+	squareDomains  []Domain
+	regions        []Region
+	dominoPairs    [][]int
+	dominoAssigned []bool
+}
+
+// Save captures the current state for later Restore.
+func (s *State) Save() *Snapshot {
+	snap := &Snapshot{
+		squareDomains:  append([]Domain(nil), s.squareDomains...),
+		regions:        make([]Region, len(s.regions)),
+		dominoPairs:    make([][]int, len(s.dominoPairs)),
+		dominoAssigned: append([]bool(nil), s.dominoAssigned...),
+	}
+	for i, r := range s.regions {
+		snap.regions[i] = *r
+	}
+	for d, pairs := range s.dominoPairs {
+		snap.dominoPairs[d] = append([]int(nil), pairs...)
+	}
+	return snap
+}
+
+// Restore undoes everything Propagate and AssignDomino changed since snap was
+// captured.
+func (s *State) Restore(snap *Snapshot) {
+	copy(s.squareDomains, snap.squareDomains)
+	for i := range s.regions {
+		*s.regions[i] = snap.regions[i]
+	}
+	for d := range s.dominoPairs {
+		s.dominoPairs[d] = snap.dominoPairs[d]
+	}
+	copy(s.dominoAssigned, snap.dominoAssigned)
+}
+
+// AssignDomino marks domino d as placed, with value1 landing on square1 and value2 on
+// square2, and restricts every other domino's remaining pairs so neither square can
+// be claimed again.
+func (s *State) AssignDomino(d, square1, value1, square2, value2 int) {
+	s.dominoAssigned[d] = true
+	s.squareDomains[square1] = domainOf(value1)
+	s.squareDomains[square2] = domainOf(value2)
+	for other := range s.dominoPairs {
+		if other == d {
+			continue
+		}
+		s.dominoPairs[other] = removePairsTouching(s.pairs, s.dominoPairs[other], square1, square2)
+	}
+}
+
+func domainOf(v int) Domain {
+	return 1 << uint(v)
+}
+
+func removePairsTouching(pairs []Pair, candidates []int, squares ...int) []int {
+	touches := func(p Pair) bool {
+		for _, sq := range squares {
+			if p.A == sq || p.B == sq {
+				return true
+			}
+		}
+		return false
+	}
+	kept := candidates[:0:0]
+	for _, pi := range candidates {
+		if !touches(pairs[pi]) {
+			kept = append(kept, pi)
+		}
+	}
+	return kept
+}
+
+// Propagate runs every rule to a fixpoint. It returns false as soon as any square's
+// domain (or any domino's candidate pair list) goes empty, so the caller can
+// backtrack immediately instead of recursing into a dead branch.
+func (s *State) Propagate() bool {
+	for {
+		changed := false
+
+		ok, didChange := s.tightenRegions()
+		if !ok {
+			return false
+		}
+		changed = changed || didChange
+
+		ok, didChange = s.tightenSupply()
+		if !ok {
+			return false
+		}
+		changed = changed || didChange
+
+		ok, didChange = s.eliminateHiddenSubsets()
+		if !ok {
+			return false
+		}
+		changed = changed || didChange
+
+		if !changed {
+			return true
+		}
+	}
+}
+
+// tightenRegions applies rule (a): gt/lt/eq/sum pruning from each region's own
+// restriction, plus eq's implicit "every unfilled square in the region must agree".
+func (s *State) tightenRegions() (ok bool, changed bool) {
+	for _, r := range s.regions {
+		for _, sq := range r.Squares {
+			d := s.squareDomains[sq]
+			if _, ok := d.Single(); ok && r.Type == RegionSum {
+				// Already pinned to one value (by AssignDomino or an earlier fixpoint
+				// pass). A sum region's Arg/NumSquaresLeft track squares not yet placed,
+				// so re-deriving this square's domain from them would double-count it.
+				// gt/lt/eq have no such running total - their Arg is a fixed target, so a
+				// pinned square still needs checking against it below: AssignDomino only
+				// guarantees the *other* domino half clears CanPlace's pair check, not
+				// that this region's own bound holds for the value it just pinned.
+				continue
+			}
+			var pruned Domain
+			switch r.Type {
+			case RegionGreaterThan:
+				pruned = d
+				for v := 0; v <= r.Arg; v++ {
+					pruned = pruned.Remove(v)
+				}
+			case RegionLessThan:
+				pruned = d
+				for v := r.Arg; v <= MaxPipValue; v++ {
+					pruned = pruned.Remove(v)
+				}
+			case RegionEqual:
+				if r.Arg == -1 {
+					pruned = d
+				} else {
+					pruned = d & domainOf(r.Arg)
+				}
+			case RegionSum:
+				pruned = 0
+				for v := 0; v <= MaxPipValue; v++ {
+					if !d.Has(v) {
+						continue
+					}
+					remainder := r.Arg - v
+					if remainder < 0 {
+						continue
+					}
+					remainingSquares := r.NumSquaresLeft - 1
+					if remainingSquares == 0 && remainder != 0 {
+						continue
+					}
+					if remainder > MaxPipValue*remainingSquares {
+						continue
+					}
+					pruned |= 1 << uint(v)
+				}
+			default:
+				pruned = d
+			}
+			if pruned != d {
+				changed = true
+				s.squareDomains[sq] = pruned
+			}
+			if pruned.Empty() {
+				return false, changed
+			}
+		}
+	}
+	return true, changed
+}
+
+// tightenSupply applies rule (b): a value can only survive in a domain if some
+// unassigned domino still carries it on one of its two halves.
+func (s *State) tightenSupply() (ok bool, changed bool) {
+	var supply Domain
+	for d, assigned := range s.dominoAssigned {
+		if assigned {
+			continue
+		}
+		supply |= domainOf(s.dominoValues[d][0])
+		supply |= domainOf(s.dominoValues[d][1])
+	}
+	for sq, d := range s.squareDomains {
+		if _, ok := d.Single(); ok {
+			// Already pinned by AssignDomino - its value came from the domino that
+			// placed it, which is now assigned and so no longer counted in supply.
+			continue
+		}
+		pruned := d & supply
+		if pruned != d {
+			changed = true
+			s.squareDomains[sq] = pruned
+		}
+		if pruned.Empty() {
+			return false, changed
+		}
+	}
+	return true, changed
+}
+
+// maxHiddenSubsetSize bounds how large a hidden subset we'll search for. Pips boards
+// are small enough that this stays cheap, and subsets larger than this essentially
+// never occur in practice.
+const maxHiddenSubsetSize = 3
+
+// eliminateHiddenSubsets applies rule (c): if exactly N unassigned dominoes can only
+// be placed across N specific pairs, no other domino may use those pairs.
+func (s *State) eliminateHiddenSubsets() (ok bool, changed bool) {
+	unassigned := make([]int, 0, len(s.dominoPairs))
+	for d, assigned := range s.dominoAssigned {
+		if !assigned {
+			unassigned = append(unassigned, d)
+		}
+	}
+
+	for size := 2; size <= maxHiddenSubsetSize && size <= len(unassigned); size++ {
+		combos := combinations(len(unassigned), size)
+		for _, combo := range combos {
+			pairSet := map[int]bool{}
+			for _, idx := range combo {
+				for _, pi := range s.dominoPairs[unassigned[idx]] {
+					pairSet[pi] = true
+				}
+			}
+			if len(pairSet) != size {
+				continue
+			}
+			inSubset := map[int]bool{}
+			for _, idx := range combo {
+				inSubset[unassigned[idx]] = true
+			}
+			for _, d := range unassigned {
+				if inSubset[d] {
+					continue
+				}
+				before := len(s.dominoPairs[d])
+				s.dominoPairs[d] = removeFromSet(s.dominoPairs[d], pairSet)
+				if len(s.dominoPairs[d]) != before {
+					changed = true
+				}
+				if len(s.dominoPairs[d]) == 0 {
+					return false, changed
+				}
+			}
+		}
+	}
+	return true, changed
+}
+
+func removeFromSet(candidates []int, remove map[int]bool) []int {
+	kept := candidates[:0:0]
+	for _, pi := range candidates {
+		if !remove[pi] {
+			kept = append(kept, pi)
+		}
+	}
+	return kept
+}
+
+// combinations returns every size-length subset of {0, ..., n-1}, as index slices.
+func combinations(n, size int) [][]int {
+	var out [][]int
+	combo := make([]int, size)
+	var rec func(start, depth int)
+	rec = func(start, depth int) {
+		if depth == size {
+			out = append(out, append([]int(nil), combo...))
+			return
+		}
+		for i := start; i < n; i++ {
+			combo[depth] = i
+			rec(i+1, depth+1)
+		}
+	}
+	rec(0, 0)
+	sort.Slice(out, func(i, j int) bool { return len(out[i]) < len(out[j]) })
+	return out
+}
@@ -0,0 +1,155 @@
+package propagate
+
+import "testing"
+
+// TestRestoreUndoesDominoAssigned guards against a bug where Snapshot/Restore
+// copied squareDomains, regions, and dominoPairs but not dominoAssigned: once a
+// domino was tried and backtracked past, tightenSupply and eliminateHiddenSubsets
+// would go on treating it as still assigned for the rest of the search, silently
+// shrinking supply and over-pruning domains that were actually still valid.
+func TestRestoreUndoesDominoAssigned(t *testing.T) {
+	// Three squares, two dominoes: squares 0-1 take one domino, square 2 is left
+	// to whichever domino didn't get used, so its domain reflects exactly which
+	// dominoes Propagate still considers unassigned.
+	s := NewState(3, nil, []Pair{{A: 0, B: 1}, {A: 1, B: 2}}, [][2]int{{1, 2}, {3, 4}})
+	snap := s.Save()
+
+	s.AssignDomino(0, 0, 1, 1, 2)
+	if !s.Propagate() {
+		t.Fatal("Propagate failed right after a legal AssignDomino")
+	}
+	if got, want := s.SquareDomain(2), domainOf(3)|domainOf(4); got != want {
+		t.Fatalf("square 2 domain = %v, want %v (domino 1's values, domino 0 used up)", got, want)
+	}
+
+	s.Restore(snap)
+
+	// Assign the *other* domino this time. If dominoAssigned wasn't restored,
+	// domino 0 would still look assigned here too, leaving no domino to supply
+	// square 2's domain at all.
+	s.AssignDomino(1, 0, 3, 1, 4)
+	if !s.Propagate() {
+		t.Fatal("Propagate failed after Restore + a fresh AssignDomino; dominoAssigned wasn't restored")
+	}
+	if got, want := s.SquareDomain(2), domainOf(1)|domainOf(2); got != want {
+		t.Fatalf("square 2 domain = %v, want %v (domino 0's values, domino 1 used up) - dominoAssigned wasn't restored", got, want)
+	}
+}
+
+// TestTightenRegionsRevalidatesPinnedSquareAgainstBound guards the propagate-side
+// half of the chunk0-1/chunk0-2 CanPlace bug: a square pinned to a single value by
+// AssignDomino must still be checked against its own gt/lt region. tightenRegions
+// used to skip every singleton domain outright (to avoid double-counting sum
+// regions' running totals), which let a gt/lt violation on a freshly pinned square
+// slip through CP undetected.
+func TestTightenRegionsRevalidatesPinnedSquareAgainstBound(t *testing.T) {
+	region := &Region{Type: RegionGreaterThan, Arg: 3, Squares: []int{0, 1}}
+	s := NewState(2, []*Region{region}, []Pair{{A: 0, B: 1}}, [][2]int{{1, 5}})
+
+	s.AssignDomino(0, 0, 1, 1, 5)
+	if ok, _ := s.tightenRegions(); ok {
+		t.Fatal("tightenRegions() = true, want false: square 0 was pinned to 1, which violates gt:3")
+	}
+}
+
+func TestTightenRegionsGreaterThan(t *testing.T) {
+	region := &Region{Type: RegionGreaterThan, Arg: 3, Squares: []int{0}}
+	s := NewState(1, []*Region{region}, nil, nil)
+
+	ok, changed := s.tightenRegions()
+	if !ok {
+		t.Fatal("tightenRegions() = false, want true: a full domain always has some value >3")
+	}
+	if !changed {
+		t.Fatal("tightenRegions() changed = false, want true: gt:3 should prune values 0-3")
+	}
+	want := FullDomain
+	for v := 0; v <= 3; v++ {
+		want = want.Remove(v)
+	}
+	if got := s.SquareDomain(0); got != want {
+		t.Fatalf("square 0 domain = %v, want %v (only values >3)", got, want)
+	}
+}
+
+func TestTightenRegionsLessThan(t *testing.T) {
+	region := &Region{Type: RegionLessThan, Arg: 3, Squares: []int{0}}
+	s := NewState(1, []*Region{region}, nil, nil)
+
+	ok, changed := s.tightenRegions()
+	if !ok {
+		t.Fatal("tightenRegions() = false, want true: a full domain always has some value <3")
+	}
+	if !changed {
+		t.Fatal("tightenRegions() changed = false, want true: lt:3 should prune values 3-6")
+	}
+	want := FullDomain
+	for v := 3; v <= MaxPipValue; v++ {
+		want = want.Remove(v)
+	}
+	if got := s.SquareDomain(0); got != want {
+		t.Fatalf("square 0 domain = %v, want %v (only values <3)", got, want)
+	}
+}
+
+func TestTightenRegionsEqual(t *testing.T) {
+	// Both squares unfilled but the region's target value already known (as it
+	// would be once one square of a larger eq region got pinned elsewhere).
+	region := &Region{Type: RegionEqual, Arg: 2, Squares: []int{0, 1}}
+	s := NewState(2, []*Region{region}, nil, nil)
+
+	ok, changed := s.tightenRegions()
+	if !ok {
+		t.Fatal("tightenRegions() = false, want true: a full domain always contains the eq target")
+	}
+	if !changed {
+		t.Fatal("tightenRegions() changed = false, want true: eq:2 should prune every value but 2")
+	}
+	if got := s.SquareDomain(0); got != domainOf(2) {
+		t.Fatalf("square 0 domain = %v, want {2}", got)
+	}
+	if got := s.SquareDomain(1); got != domainOf(2) {
+		t.Fatalf("square 1 domain = %v, want {2}", got)
+	}
+}
+
+func TestTightenRegionsSum(t *testing.T) {
+	// Two unfilled squares that must sum to 5: square 0 can't hold 6, since that
+	// would force the other square to -1.
+	region := &Region{Type: RegionSum, Arg: 5, NumSquaresLeft: 2, Squares: []int{0, 1}}
+	s := NewState(2, []*Region{region}, nil, nil)
+
+	ok, changed := s.tightenRegions()
+	if !ok {
+		t.Fatal("tightenRegions() = false, want true: sum:5 over two squares is satisfiable")
+	}
+	if !changed {
+		t.Fatal("tightenRegions() changed = false, want true: sum:5 should rule out 6")
+	}
+	want := FullDomain.Remove(6)
+	if got := s.SquareDomain(0); got != want {
+		t.Fatalf("square 0 domain = %v, want %v (6 would force the other square below 0)", got, want)
+	}
+}
+
+// TestEliminateHiddenSubsetsNarrowsThirdDominoOut constructs a hidden-pair case by
+// board shape: two dominoes' candidate pairs have already been narrowed (elsewhere)
+// down to the same two pairs, leaving a third pair those two dominoes never use. No
+// domino outside that hidden pair may use the two pairs the hidden set has claimed.
+func TestEliminateHiddenSubsetsNarrowsThirdDominoOut(t *testing.T) {
+	// 4 squares in a row: pair 0 = {0,1}, pair 1 = {1,2}, pair 2 = {2,3}.
+	s := NewState(4, nil, []Pair{{A: 0, B: 1}, {A: 1, B: 2}, {A: 2, B: 3}}, [][2]int{{1, 2}, {3, 4}, {5, 6}})
+	s.dominoPairs[0] = []int{0, 2}
+	s.dominoPairs[1] = []int{0, 2}
+
+	ok, changed := s.eliminateHiddenSubsets()
+	if !ok {
+		t.Fatal("eliminateHiddenSubsets() = false, want true: domino 2 still has pair 1 available")
+	}
+	if !changed {
+		t.Fatal("eliminateHiddenSubsets() changed = false, want true: dominoes 0 and 1 are a hidden pair over pairs {0,2}")
+	}
+	if got := s.dominoPairs[2]; len(got) != 1 || got[0] != 1 {
+		t.Fatalf("domino 2's candidate pairs = %v, want only pair 1 (pairs 0 and 2 belong to the hidden pair)", got)
+	}
+}